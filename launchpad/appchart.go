@@ -0,0 +1,98 @@
+package launchpad
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// appChartValues are the values that launchpad sets for launchpad.yaml
+// features that older app charts don't read. The app chart released with the
+// same version as launchpad (appChartVersion) reads them all, but a chart
+// from --helm.app.chart-location may be older, and deploying with it would
+// silently ignore those features.
+var appChartValues = []valueKeyPath{
+	{"command"},               // workers
+	{"service", "enabled"},    // workers
+	{"ambassador", "enabled"}, // internal services and workers
+	{"readinessProbe"},
+	{"livenessProbe"},
+	{"startupProbe"},
+	{"autoscaling", "enabled"},
+	{"deployment", "enabled"}, // pre-deploy jobs
+	{"env"},
+	{"volumes"},      // config files and volumes
+	{"volumeMounts"}, // config files and volumes
+	{"initContainers"},
+	{"sidecars"},
+	{"nodeSelector"},
+	{"tolerations"},
+	{"affinity"},
+	{"topologySpreadConstraints"},
+	{"labels"},
+	{"annotations"},
+	{"commonLabels"},
+	{"commonAnnotations"},
+}
+
+// checkAppChartValues returns a user error if cc is a release of the app chart
+// and c doesn't read values that launchpad sets for it. Charts are assumed to
+// read a value if a template or subchart template refers to .Values.<path>.
+func checkAppChartValues(c *chart.Chart, cc *ChartConfig) error {
+	if cc.Name != AppChartName || cc.Repo != "" {
+		return nil
+	}
+	templates := appChartTemplates(c)
+	unsupported := []string{}
+	for _, path := range appChartValues {
+		if !hasValue(cc.Values, path) {
+			continue
+		}
+		name := strings.Join(path, ".")
+		ref := regexp.MustCompile(`\.Values\.` + regexp.QuoteMeta(name) + `\b`)
+		if !ref.MatchString(templates) {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return errorutil.NewUserErrorf(
+		"Version %s of chart %s doesn't support values %s, which %s needs. Use "+
+			"version %s of the app chart, or a chart based on it",
+		c.Metadata.Version,
+		c.Name(),
+		strings.Join(unsupported, ", "),
+		cc.HumanName(),
+		appChartVersion,
+	)
+}
+
+// appChartTemplates returns the templates of c and its subcharts.
+func appChartTemplates(c *chart.Chart) string {
+	templates := lo.Map(c.Templates, func(f *chart.File, _ int) string { return string(f.Data) })
+	for _, dep := range c.Dependencies() {
+		templates = append(templates, appChartTemplates(dep))
+	}
+	return strings.Join(templates, "\n")
+}
+
+// hasValue returns true if values has a value at path.
+func hasValue(values map[string]any, path valueKeyPath) bool {
+	for i, key := range path {
+		v, ok := values[key]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if values, ok = v.(map[string]any); !ok {
+			return false
+		}
+	}
+	return false
+}
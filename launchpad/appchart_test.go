@@ -0,0 +1,55 @@
+package launchpad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestCheckAppChartValues(t *testing.T) {
+	appChart := func(templates ...string) *chart.Chart {
+		c := &chart.Chart{Metadata: &chart.Metadata{Name: AppChartName, Version: "0.1.0"}}
+		for _, data := range templates {
+			c.Templates = append(c.Templates, &chart.File{Name: "templates/t.yaml", Data: []byte(data)})
+		}
+		return c
+	}
+	cc := &ChartConfig{
+		Name:         AppChartName,
+		instanceName: "my-app-api",
+		Values: map[string]any{
+			"image":          map[string]any{"repository": "api"},
+			"readinessProbe": map[string]any{"httpGet": map[string]any{"path": "/"}},
+			"autoscaling":    map[string]any{"enabled": false},
+		},
+	}
+
+	// Values that launchpad always set, such as image, aren't checked
+	old := appChart(`image: {{ .Values.image.repository }}`)
+	assert.Contains(
+		t,
+		errorutil.GetUserErrorMessage(checkAppChartValues(old, cc)),
+		"Version 0.1.0 of chart app doesn't support values readinessProbe, "+
+			"autoscaling.enabled, which my-app-api needs",
+	)
+
+	// Values can be read by subcharts
+	current := appChart(
+		`{{- with .Values.readinessProbe }}{{ toYaml . }}{{ end }}`,
+		`{{- if not .Values.livenessProbe }}{{ end }}`,
+	)
+	current.AddDependency(appChart(`{{- if .Values.autoscaling.enabled }}{{ end }}`))
+	assert.NoError(t, checkAppChartValues(current, cc))
+
+	// A reference to a longer name doesn't count
+	assert.Error(t, checkAppChartValues(
+		appChart(`{{ .Values.readinessProbeX }} {{ .Values.autoscaling.enabled }}`),
+		cc,
+	))
+
+	// Only releases of the app chart are checked
+	external := &ChartConfig{Name: AppChartName, Repo: "https://charts.example.com", Values: cc.Values}
+	assert.NoError(t, checkAppChartValues(old, external))
+}
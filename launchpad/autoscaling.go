@@ -0,0 +1,236 @@
+package launchpad
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	hpaApiVersionV2Beta2 = "autoscaling/v2beta2"
+	// fieldManager is the field manager and managed-by label of the resources
+	// that launchpad applies itself rather than through helm.
+	fieldManager = "launchpad"
+)
+
+// Autoscaling describes the HorizontalPodAutoscaler of an app chart release.
+type Autoscaling struct {
+	MinReplicas int
+	MaxReplicas int
+	// Target average utilization, as a percentage of the requests. Zero means
+	// the resource is not used for scaling.
+	TargetCPU    int
+	TargetMemory int
+	// Labels and Annotations of the HorizontalPodAutoscaler, besides the ones
+	// launchpad sets.
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// ReplicaStatus is the number of replicas of an app chart release's
+// deployment.
+type ReplicaStatus struct {
+	Ready   int
+	Desired int
+	// Autoscaling is nil if the release has a fixed number of replicas.
+	Autoscaling *Autoscaling
+}
+
+// appDeploymentName returns the name of the deployment that the app chart
+// creates for the given instance. It's named like the service.
+func appDeploymentName(instanceName string) string {
+	return AppServiceName(instanceName)
+}
+
+// setAutoscaledReplicas sets the replicaCount of each app release that
+// autoscales to the current replicas of its deployment, so that upgrading the
+// release doesn't undo what the HPA scaled it to. New deployments start with
+// the minimum.
+func setAutoscaledReplicas(ctx context.Context, plan *DeployPlan) error {
+	if lo.NoneBy(plan.appCharts(), func(cc *ChartConfig) bool { return cc.autoscaling != nil }) {
+		return nil
+	}
+	rc, err := RESTConfigFromDefaults(plan.DeployOptions.KubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s clientset")
+	}
+	for _, cc := range plan.appCharts() {
+		if cc.autoscaling == nil {
+			continue
+		}
+		replicas, err := currentReplicas(ctx, clientset, cc)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		cc.Values["replicaCount"] = replicas
+	}
+	return nil
+}
+
+// currentReplicas returns the replicas of the deployment of an app release that
+// autoscales, within its autoscaling range, or the minimum if the deployment
+// doesn't exist yet.
+func currentReplicas(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	cc *ChartConfig,
+) (int, error) {
+	name := appDeploymentName(cc.instanceName)
+	deployment, err := clientset.AppsV1().Deployments(cc.Namespace).Get(
+		ctx,
+		name,
+		metav1.GetOptions{},
+	)
+	if k8sErrors.IsNotFound(err) || (err == nil && deployment.Spec.Replicas == nil) {
+		return cc.autoscaling.MinReplicas, nil
+	} else if err != nil {
+		return 0, errors.Wrapf(err, "failed to get deployment %s", name)
+	}
+	return lo.Clamp(
+		int(*deployment.Spec.Replicas),
+		cc.autoscaling.MinReplicas,
+		cc.autoscaling.MaxReplicas,
+	), nil
+}
+
+// applyAutoscalers creates or updates the HPA of each app release that
+// autoscales, and deletes it from releases that no longer do. It returns the
+// replica status of each app release that has a deployment, keyed by instance
+// name.
+func applyAutoscalers(ctx context.Context, plan *DeployPlan) (map[string]*ReplicaStatus, error) {
+	rc, err := RESTConfigFromDefaults(plan.DeployOptions.KubeContext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s client rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create k8s clientset")
+	}
+	dynamicClient, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create k8s dynamic client")
+	}
+
+	apiVersion := ""
+	statuses := map[string]*ReplicaStatus{}
+	apps := append([]*ChartConfig{plan.appChartConfig}, plan.additionalAppChartConfigs...)
+	for _, cc := range apps {
+		name := appDeploymentName(cc.instanceName)
+		if cc.autoscaling == nil {
+			err := clientset.AutoscalingV1().HorizontalPodAutoscalers(cc.Namespace).Delete(
+				ctx,
+				name,
+				metav1.DeleteOptions{},
+			)
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "failed to delete autoscaler %s", name)
+			}
+		} else {
+			if apiVersion == "" {
+				apiVersion = hpaApiVersion(clientset.Discovery())
+			}
+			hpa := newHPA(cc, apiVersion)
+			manifest, err := reaktor.ToManifest(hpa)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			gv, err := schema.ParseGroupVersion(apiVersion)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			_, err = dynamicClient.
+				Resource(gv.WithResource("horizontalpodautoscalers")).
+				Namespace(cc.Namespace).
+				Apply(ctx, name, manifest, metav1.ApplyOptions{
+					FieldManager: fieldManager,
+					Force:        true,
+				})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to apply autoscaler %s", name)
+			}
+		}
+
+		deployment, err := clientset.AppsV1().Deployments(cc.Namespace).Get(
+			ctx,
+			name,
+			metav1.GetOptions{},
+		)
+		if k8sErrors.IsNotFound(err) {
+			// e.g. projects without web services, or with zero replicas
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get deployment %s", name)
+		}
+		status := &ReplicaStatus{
+			Ready:       int(deployment.Status.ReadyReplicas),
+			Autoscaling: cc.autoscaling,
+		}
+		if deployment.Spec.Replicas != nil {
+			status.Desired = int(*deployment.Spec.Replicas)
+		}
+		statuses[cc.instanceName] = status
+	}
+	return statuses, nil
+}
+
+// hpaApiVersion returns autoscaling/v2 if the cluster serves it (kubernetes
+// 1.23 and later), or autoscaling/v2beta2 otherwise.
+func hpaApiVersion(dc discovery.DiscoveryInterface) string {
+	if _, err := dc.ServerResourcesForGroupVersion(komponents.DefaultHPAApiVersion); err != nil {
+		return hpaApiVersionV2Beta2
+	}
+	return komponents.DefaultHPAApiVersion
+}
+
+func newHPA(cc *ChartConfig, apiVersion string) *komponents.HorizontalPodAutoscaler {
+	name := appDeploymentName(cc.instanceName)
+	hpa := &komponents.HorizontalPodAutoscaler{
+		ApiVersion:  apiVersion,
+		Name:        name,
+		Namespace:   cc.Namespace,
+		Labels:      lo.Assign(cc.autoscaling.Labels, managedLabels(cc.instanceName)),
+		Annotations: cc.autoscaling.Annotations,
+		ScaleTargetRef: komponents.ScaleTargetRef{
+			ApiVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       name,
+		},
+		MinReplicas: cc.autoscaling.MinReplicas,
+		MaxReplicas: cc.autoscaling.MaxReplicas,
+	}
+	for _, metric := range []struct {
+		resource string
+		target   int
+	}{
+		{"cpu", cc.autoscaling.TargetCPU},
+		{"memory", cc.autoscaling.TargetMemory},
+	} {
+		if metric.target == 0 {
+			continue
+		}
+		hpa.Metrics = append(hpa.Metrics, komponents.Metric{
+			Type: "Resource",
+			Resource: komponents.MetricResource{
+				Name: metric.resource,
+				Target: map[string]any{
+					"type":               "Utilization",
+					"averageUtilization": metric.target,
+				},
+			},
+		})
+	}
+	return hpa
+}
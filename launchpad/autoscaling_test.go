@@ -0,0 +1,158 @@
+package launchpad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+func TestHPAApiVersion(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	assert.Equal(t, "autoscaling/v2beta2", hpaApiVersion(dc))
+
+	dc.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "autoscaling/v2",
+		APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers"}},
+	}}
+	assert.Equal(t, "autoscaling/v2", hpaApiVersion(dc))
+}
+
+func TestNewHPA(t *testing.T) {
+	cc := &ChartConfig{
+		instanceName: "my-app-api",
+		Namespace:    "my-ns",
+		autoscaling: &Autoscaling{
+			MinReplicas:  2,
+			MaxReplicas:  10,
+			TargetMemory: 75,
+			Labels: map[string]string{
+				"team":                       "payments",
+				"app.kubernetes.io/instance": "other",
+			},
+			Annotations: map[string]string{"example.com/owner": "payments@example.com"},
+		},
+	}
+	manifest, err := reaktor.ToManifest(newHPA(cc, "autoscaling/v2beta2"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "autoscaling/v2beta2", manifest.GetAPIVersion())
+	assert.Equal(t, "my-app-api-app", manifest.GetName())
+	labels := manifest.Object["metadata"].(map[string]any)["labels"].(map[string]string)
+	assert.Equal(t, "my-app-api", labels["app.kubernetes.io/instance"])
+	assert.Equal(t, "payments", labels["team"])
+	assert.Equal(
+		t,
+		map[string]string{"example.com/owner": "payments@example.com"},
+		manifest.GetAnnotations(),
+	)
+
+	target, _, _ := unstructured.NestedString(manifest.Object, "spec", "scaleTargetRef", "name")
+	assert.Equal(t, "my-app-api-app", target)
+	assert.Equal(t, 2, manifest.Object["spec"].(map[string]any)["minReplicas"])
+	assert.Equal(t, 10, manifest.Object["spec"].(map[string]any)["maxReplicas"])
+
+	metrics := manifest.Object["spec"].(map[string]any)["metrics"].([]map[string]any)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "memory", metrics[0]["resource"].(map[string]any)["name"])
+	assert.Equal(
+		t,
+		map[string]any{"type": "Utilization", "averageUtilization": 75},
+		metrics[0]["resource"].(map[string]any)["target"],
+	)
+}
+
+func TestCurrentReplicas(t *testing.T) {
+	ctx := context.Background()
+	cc := &ChartConfig{
+		instanceName: "my-app-api",
+		Namespace:    "my-ns",
+		autoscaling:  &Autoscaling{MinReplicas: 2, MaxReplicas: 10},
+	}
+	deployment := func(replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-app-api-app", Namespace: "my-ns"},
+			Spec:       appsv1.DeploymentSpec{Replicas: lo.ToPtr(replicas)},
+		}
+	}
+
+	// A new deployment starts with the minimum
+	replicas, err := currentReplicas(ctx, fake.NewSimpleClientset(), cc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, replicas)
+
+	// The HPA scaled it up
+	replicas, err = currentReplicas(ctx, fake.NewSimpleClientset(deployment(7)), cc)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, replicas)
+
+	// The new max is lower than the current replicas
+	replicas, err = currentReplicas(ctx, fake.NewSimpleClientset(deployment(12)), cc)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, replicas)
+}
+
+// TestAutoscaledReplicasInManifest checks that an upgrade of an autoscaled
+// release renders the deployment with its current replicas, rather than the
+// chart's default replicaCount.
+func TestAutoscaledReplicasInManifest(t *testing.T) {
+	appChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "0.1.0"},
+		Values: map[string]any{
+			"replicaCount": 1,
+			"autoscaling":  map[string]any{"enabled": true},
+		},
+		Templates: []*chart.File{{
+			Name: "templates/deployment.yaml",
+			Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-app
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+`),
+		}},
+	}
+	cc := &ChartConfig{
+		instanceName: "my-app-api",
+		Namespace:    "my-ns",
+		Values:       map[string]any{"autoscaling": map[string]any{"enabled": false}},
+		autoscaling:  &Autoscaling{MinReplicas: 2, MaxReplicas: 10},
+	}
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app-api-app", Namespace: "my-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: lo.ToPtr(int32(7))},
+	})
+	replicas, err := currentReplicas(context.Background(), clientset, cc)
+	assert.NoError(t, err)
+	cc.Values["replicaCount"] = replicas
+
+	values, err := chartutil.ToRenderValues(
+		appChart,
+		cc.Values,
+		chartutil.ReleaseOptions{Name: cc.instanceName, Namespace: cc.Namespace, IsUpgrade: true},
+		chartutil.DefaultCapabilities,
+	)
+	assert.NoError(t, err)
+	manifests, err := engine.Render(appChart, values)
+	assert.NoError(t, err)
+
+	rendered := appsv1.Deployment{}
+	assert.NoError(t, yaml.Unmarshal([]byte(manifests["app/templates/deployment.yaml"]), &rendered))
+	assert.Equal(t, "my-app-api-app", rendered.Name)
+	assert.Equal(t, lo.ToPtr(int32(7)), rendered.Spec.Replicas)
+}
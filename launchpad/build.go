@@ -22,6 +22,8 @@ import (
 	"go.jetpack.io/launchpad/pkg/docker"
 	"go.jetpack.io/launchpad/pkg/jetlog"
 	"go.jetpack.io/launchpad/pkg/kubevalidate"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -94,20 +96,68 @@ type BuildPlan struct {
 	// projectDir is the absolute path to the directory of the project
 	projectDir string
 
+	// serviceImages are the images of services with their own build, keyed by
+	// service name.
+	serviceImages map[string]*serviceImagePlan
+
 	buildOpts *BuildOptions
 }
 
+// serviceImagePlan is the build of a service with its own Dockerfile or build
+// context.
+type serviceImagePlan struct {
+	args           map[string]string
+	contextDir     string // absolute
+	dockerfilePath string // absolute
+	image          *LocalImage
+	target         string
+}
+
 func (p *BuildPlan) requiresDockerfile() bool {
 	return planShouldUseDockerfile(p.buildOpts)
 }
 
 type BuildOutput struct {
 	Duration time.Duration
-	Image    *LocalImage
+	// Image is built from the project's Dockerfile. It's used by every service
+	// that has neither an image nor a build of its own.
+	Image *LocalImage
+	// ServiceImages are the images of services with their own build, keyed by
+	// service name.
+	ServiceImages map[string]*LocalImage
 }
 
 func (o *BuildOutput) DidBuildUsingDockerfile() bool {
-	return o != nil && o.Image != nil && *o.Image != ""
+	return o != nil && (o.Image != nil && *o.Image != "" || len(o.ServiceImages) > 0)
+}
+
+// Images returns the project's image, if any, followed by the image of each
+// service with its own build, in service name order.
+func (o *BuildOutput) Images() []*LocalImage {
+	if o == nil {
+		return nil
+	}
+	images := []*LocalImage{}
+	if o.Image != nil && *o.Image != "" {
+		images = append(images, o.Image)
+	}
+	names := maps.Keys(o.ServiceImages)
+	slices.Sort(names)
+	for _, name := range names {
+		images = append(images, o.ServiceImages[name])
+	}
+	return images
+}
+
+// ServiceLocalImages returns the local image of each service with its own
+// build, keyed by service name.
+func (o *BuildOutput) ServiceLocalImages() map[string]string {
+	if o == nil {
+		return nil
+	}
+	return lo.MapValues(o.ServiceImages, func(i *LocalImage, _ string) string {
+		return i.String()
+	})
 }
 
 func (o *BuildOutput) SetDuration(d time.Duration) {
@@ -138,7 +188,13 @@ func build(
 		return nil, errors.Wrap(err, "failed to execute build plan")
 	}
 
-	return &BuildOutput{Image: plan.image}, nil
+	return &BuildOutput{
+		Image: plan.image,
+		ServiceImages: lo.MapValues(
+			plan.serviceImages,
+			func(p *serviceImagePlan, _ string) *LocalImage { return p.image },
+		),
+	}, nil
 }
 
 func makeBuildPlan(
@@ -174,11 +230,36 @@ func makeBuildPlan(
 		}
 	}
 
+	serviceImages := map[string]*serviceImagePlan{}
+	if opts.LocalImage == "" {
+		for name, svc := range opts.Services {
+			build := svc.GetBuild()
+			if build == nil {
+				continue
+			}
+			imageName, err := kubevalidate.ToValidName(
+				filepath.Base(opts.AppName) + "-" + name,
+			)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			serviceImages[name] = &serviceImagePlan{
+				// --build-arg flags take precedence
+				args:           lo.Assign(build.Args, opts.BuildArgs),
+				contextDir:     filepath.Join(svc.GetPath(), build.GetContext()),
+				dockerfilePath: filepath.Join(svc.GetPath(), build.GetDockerfile()),
+				image:          newLocalImageWithTag(imageName, generateDateImageTag(opts.TagPrefix)),
+				target:         build.Target,
+			}
+		}
+	}
+
 	plan := &BuildPlan{
 		dockerfilePath: dockerfilePath,
 		image:          newLocalImageWithTag(imageName, imageTag),
 		projectDir:     opts.ProjectDir,
 		imageLabels:    map[string]string{dockerProjectIdLabel: opts.ProjectId},
+		serviceImages:  serviceImages,
 		buildOpts:      opts,
 	}
 	return plan, nil
@@ -192,7 +273,7 @@ func planShouldUseDockerfile(opts *BuildOptions) bool {
 	hasServiceWithEmptyImage := lo.SomeBy(
 		lo.Values(opts.Services),
 		func(s jetconfig.Builder) bool {
-			return s.GetImage() == ""
+			return s.GetImage() == "" && s.GetBuild() == nil
 		},
 	)
 
@@ -239,6 +320,18 @@ func validateBuildPlan(plan *BuildPlan, fs afero.Fs) error {
 		)
 	}
 
+	for name, p := range plan.serviceImages {
+		if _, err := fs.Stat(p.dockerfilePath); os.IsNotExist(err) {
+			return errorutil.NewUserErrorf(
+				"Dockerfile %s of service %s does not exist",
+				p.dockerfilePath,
+				name,
+			)
+		} else if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	return nil
 }
 
@@ -271,6 +364,14 @@ func executeBuildPlan(ctx context.Context, plan *BuildPlan) error {
 		}
 	}
 
+	names := maps.Keys(plan.serviceImages)
+	slices.Sort(names)
+	for _, name := range names {
+		if err := executeServiceImagePlan(ctx, plan, plan.serviceImages[name]); err != nil {
+			return errors.Wrapf(err, "failed to build image of service %s", name)
+		}
+	}
+
 	return nil
 }
 
@@ -293,6 +394,34 @@ func executePlanUsingDocker(ctx context.Context, plan *BuildPlan) (err error) {
 	return docker.Build(ctx, filepath.Dir(plan.dockerfilePath), imageBuildOptions)
 }
 
+func executeServiceImagePlan(
+	ctx context.Context,
+	plan *BuildPlan,
+	p *serviceImagePlan,
+) error {
+	jetlog.Logger(ctx).IndentedPrintf(
+		"Building Docker image %s with Dockerfile at: %s\n",
+		p.image,
+		p.dockerfilePath,
+	)
+
+	// docker.Build expects the Dockerfile relative to the context
+	dockerfile, err := filepath.Rel(p.contextDir, p.dockerfilePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return docker.Build(ctx, p.contextDir, docker.BuildOpts{
+		BuildArgs: lo.MapValues(p.args, func(val, _ string) *string {
+			return &val
+		}),
+		Dockerfile: dockerfile,
+		Labels:     plan.imageLabels,
+		Platform:   plan.buildOpts.Platform,
+		Tags:       []string{p.image.String()},
+		Target:     p.target,
+	})
+}
+
 // getImageNameAndTag returns a valid docker image name
 // and its imageTag is appended.
 func getImageNameAndTag(ctx context.Context, opts *BuildOptions) (string, string, error) {
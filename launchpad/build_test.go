@@ -0,0 +1,87 @@
+package launchpad
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetpack.io/launchpad/padcli/jetconfig"
+)
+
+func TestMakeBuildPlanWithServiceBuilds(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "launchpad.yaml"), []byte(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: monorepo
+cluster: my-cluster
+services:
+  api:
+    type: web
+    build:
+      dockerfile: services/api/Dockerfile
+      target: release
+      args:
+        A: from-config
+        B: from-config
+  consumer:
+    type: worker
+    image: busybox
+`), 0644))
+	ctx := context.Background()
+	jetCfg, err := jetconfig.RequireFromFileSystem(ctx, dir, "dev")
+	require.NoError(t, err)
+
+	plan, err := makeBuildPlan(ctx, &BuildOptions{
+		AppName:    "monorepo",
+		BuildArgs:  map[string]string{"B": "from-flag"},
+		ProjectDir: dir,
+		Services:   jetCfg.Builders(),
+	})
+	require.NoError(t, err)
+
+	// No service uses the project's Dockerfile
+	assert.Empty(t, plan.dockerfilePath)
+	assert.False(t, plan.requiresDockerfile())
+	require.Len(t, plan.serviceImages, 1)
+	api := plan.serviceImages["api"]
+	assert.Equal(t, dir, api.contextDir)
+	assert.Equal(t, filepath.Join(dir, "services/api/Dockerfile"), api.dockerfilePath)
+	assert.Equal(t, "release", api.target)
+	assert.Equal(t, map[string]string{"A": "from-config", "B": "from-flag"}, api.args)
+	assert.Equal(t, "monorepo-api", api.image.Name())
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll(dir, 0755))
+	assert.ErrorContains(t, validateBuildPlan(plan, fs), "Dockerfile "+api.dockerfilePath+" of service api does not exist")
+	require.NoError(t, afero.WriteFile(fs, api.dockerfilePath, []byte("FROM busybox\n"), 0644))
+	assert.NoError(t, validateBuildPlan(plan, fs))
+}
+
+func TestBuildOutputImages(t *testing.T) {
+	out := &BuildOutput{
+		Image: NewLocalImage("app:1"),
+		ServiceImages: map[string]*LocalImage{
+			"worker": NewLocalImage("app-worker:1"),
+			"api":    NewLocalImage("app-api:1"),
+		},
+	}
+	assert.True(t, out.DidBuildUsingDockerfile())
+	assert.Equal(
+		t,
+		[]*LocalImage{NewLocalImage("app:1"), NewLocalImage("app-api:1"), NewLocalImage("app-worker:1")},
+		out.Images(),
+	)
+	assert.Equal(
+		t,
+		map[string]string{"api": "app-api:1", "worker": "app-worker:1"},
+		out.ServiceLocalImages(),
+	)
+
+	out = &BuildOutput{ServiceImages: map[string]*LocalImage{"api": NewLocalImage("app-api:1")}}
+	assert.True(t, out.DidBuildUsingDockerfile())
+	assert.Equal(t, []*LocalImage{NewLocalImage("app-api:1")}, out.Images())
+}
@@ -0,0 +1,96 @@
+package launchpad
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var configMapResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// applyConfigMaps creates or updates the ConfigMaps of each app release, and
+// deletes the ones that launchpad created for a release before but that are no
+// longer needed, e.g. because a service no longer has config files.
+func applyConfigMaps(ctx context.Context, plan *DeployPlan) error {
+	rc, err := RESTConfigFromDefaults(plan.DeployOptions.KubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s clientset")
+	}
+	dynamicClient, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s dynamic client")
+	}
+
+	for _, cc := range plan.appCharts() {
+		names := map[string]bool{}
+		for _, cm := range cc.configMaps {
+			cm.Namespace = cc.Namespace
+			cm.Labels = lo.Assign(cm.Labels, managedLabels(cc.instanceName))
+			manifest, err := reaktor.ToManifest(cm)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = dynamicClient.
+				Resource(configMapResource).
+				Namespace(cc.Namespace).
+				Apply(ctx, cm.Name, manifest, metav1.ApplyOptions{
+					FieldManager: fieldManager,
+					Force:        true,
+				})
+			if err != nil {
+				return errors.Wrapf(err, "failed to apply config map %s", cm.Name)
+			}
+			names[cm.Name] = true
+		}
+
+		existing, err := clientset.CoreV1().ConfigMaps(cc.Namespace).List(
+			ctx,
+			metav1.ListOptions{LabelSelector: managedLabelSelector(cc.instanceName)},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list config maps of %s", cc.instanceName)
+		}
+		for _, cm := range existing.Items {
+			if names[cm.Name] {
+				continue
+			}
+			err := clientset.CoreV1().ConfigMaps(cc.Namespace).Delete(
+				ctx,
+				cm.Name,
+				metav1.DeleteOptions{},
+			)
+			if err != nil {
+				return errors.Wrapf(err, "failed to delete config map %s", cm.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// managedLabels are the labels of resources that launchpad applies for an app
+// release. They match the labels of the app chart, so that down deletes them.
+func managedLabels(instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":   instanceName,
+		"app.kubernetes.io/managed-by": fieldManager,
+	}
+}
+
+func managedLabelSelector(instanceName string) string {
+	return fmt.Sprintf(
+		"app.kubernetes.io/instance=%s,app.kubernetes.io/managed-by=%s",
+		instanceName,
+		fieldManager,
+	)
+}
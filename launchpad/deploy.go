@@ -12,6 +12,7 @@ import (
 
 	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/sethvargo/go-password/password"
 	"go.jetpack.io/launchpad/goutil"
 	"go.jetpack.io/launchpad/goutil/errorutil"
@@ -19,6 +20,7 @@ import (
 	"go.jetpack.io/launchpad/padcli/jetconfig"
 	"go.jetpack.io/launchpad/pkg/buildstamp"
 	"go.jetpack.io/launchpad/pkg/jetlog"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
 	"go.jetpack.io/launchpad/proto/api"
 	"golang.org/x/sync/errgroup"
 	"helm.sh/helm/v3/pkg/cli"
@@ -37,6 +39,8 @@ const (
 	ApiKeySecretName = "api-key-secret"
 )
 
+// appChartVersion is the version of the app chart that's released with this
+// version of launchpad. It reads every value in appChartValues.
 var appChartVersion = buildstamp.StableDockerTag
 var runtimeChartVersion = buildstamp.StableDockerTag
 
@@ -44,7 +48,16 @@ var runtimeChartVersion = buildstamp.StableDockerTag
 type DeployOptions struct {
 	App *HelmOptions
 
-	Environment string // api.Environment
+	// AdditionalApps are extra releases of the app chart, one per web service
+	// beyond the first and one per internal service or worker.
+	AdditionalApps []*HelmOptions
+
+	// PreDeploy is the release of the app chart that runs pre-deploy jobs. It's
+	// installed, and its jobs must succeed, before any other app release. Nil
+	// if there are no pre-deploy jobs.
+	PreDeploy *HelmOptions
+
+	Environment string // api.Environment, or an uppercase environment declared in jetconfig
 
 	ExternalCharts []*ChartConfig
 
@@ -55,6 +68,10 @@ type DeployOptions struct {
 	// requirements. Leaving jetconfig in for now.
 	JetCfg *jetconfig.Config
 
+	// How long finished jobs are kept. Defaults to 10 minutes in dev and 24
+	// hours otherwise.
+	JobRetention gotime.Duration
+
 	KubeContext string
 
 	LifecycleHook hook.LifecycleHook
@@ -72,27 +89,47 @@ type DeployOptions struct {
 
 type ChartConfig struct {
 	Repo      string
-	Name      string
+	Name      string // chart name in Repo, or an oci:// reference if Repo is empty
 	Namespace string
 	Release   string // unique identifier for installation (can be same or different from display name)
 	Timeout   gotime.Duration
+	Values    map[string]any
+	Version   string // chart version. Empty means the latest
 	Wait      bool
 
-	chartLocation string // optional path to local chart
-	chartVersion  string
-	instanceName  string // resources will inherit this name
-	values        map[string]any
+	autoscaling   *Autoscaling                        // app chart only
+	chartLocation string                              // optional path to local chart
+	configMaps    []*komponents.ConfigMap             // app chart only
+	domains       *Domains                            // app chart only
+	volumeClaims  []*komponents.PersistentVolumeClaim // app chart only
+	instanceName  string                              // resources will inherit this name
+	key           string                              // optional key in DeployOutput.Releases. Defaults to Name
 }
 
 func (c *ChartConfig) HumanName() string {
 	return goutil.Coalesce(c.instanceName, c.Name)
 }
 
+func (c *ChartConfig) releaseKey() string {
+	return goutil.Coalesce(c.key, c.Name)
+}
+
 type DeployPlan struct {
-	DeployOptions      *DeployOptions
-	appChartConfig     *ChartConfig
-	runtimeChartConfig *ChartConfig
-	helmDriver         string
+	DeployOptions             *DeployOptions
+	appChartConfig            *ChartConfig
+	additionalAppChartConfigs []*ChartConfig
+	preDeployChartConfig      *ChartConfig
+	runtimeChartConfig        *ChartConfig
+	helmDriver                string
+}
+
+// appCharts returns the releases of the app chart.
+func (dp *DeployPlan) appCharts() []*ChartConfig {
+	charts := append([]*ChartConfig{dp.appChartConfig}, dp.additionalAppChartConfigs...)
+	if dp.preDeployChartConfig != nil {
+		charts = append(charts, dp.preDeployChartConfig)
+	}
+	return charts
 }
 
 func (dp *DeployPlan) Charts() []*ChartConfig {
@@ -101,10 +138,13 @@ func (dp *DeployPlan) Charts() []*ChartConfig {
 	if dp.runtimeChartConfig != nil {
 		charts = append(charts, dp.runtimeChartConfig)
 	}
+	if dp.preDeployChartConfig != nil {
+		charts = append(charts, dp.preDeployChartConfig)
+	}
 	if dp.appChartConfig != nil {
 		charts = append(charts, dp.appChartConfig)
 	}
-	return charts
+	return append(charts, dp.additionalAppChartConfigs...)
 }
 
 type DeployOutput struct {
@@ -112,6 +152,33 @@ type DeployOutput struct {
 	InstanceName string
 	Namespace    string
 	Releases     map[string]*release.Release // keyed by unique chart name
+
+	// AdditionalApps are the keys in Releases of the app chart releases
+	// beyond the main one.
+	AdditionalApps []string
+
+	// Replicas of each app release that has a deployment, keyed by instance
+	// name.
+	Replicas map[string]*ReplicaStatus
+
+	// DNSRecords are the records that the custom domains of web services need
+	// to reach the cluster.
+	DNSRecords []DNSRecord
+}
+
+// AppReleases returns the main app release followed by the release of each
+// additional web service, internal service and worker.
+func (do *DeployOutput) AppReleases() []*release.Release {
+	if do == nil {
+		return nil
+	}
+	releases := []*release.Release{}
+	for _, key := range append([]string{AppChartName}, do.AdditionalApps...) {
+		if r := do.Releases[key]; r != nil {
+			releases = append(releases, r)
+		}
+	}
+	return releases
 }
 
 func (do *DeployOutput) AppPort() int {
@@ -135,6 +202,18 @@ func (do *DeployOutput) AppPort() int {
 	return defaultPodPort
 }
 
+// AppServiceName returns the name of the kubernetes service that the app chart
+// creates for the given instance.
+func AppServiceName(instanceName string) string {
+	return instanceName + "-" + AppChartName
+}
+
+// AppServiceHostname returns the stable in-cluster DNS name of the kubernetes
+// service that the app chart creates for the given instance.
+func AppServiceHostname(instanceName string, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", AppServiceName(instanceName), namespace)
+}
+
 func (do *DeployOutput) SetDuration(d gotime.Duration) {
 	if do != nil {
 		do.Duration = d
@@ -155,15 +234,41 @@ func (p *Pad) deploy(
 		return nil, errors.Wrap(err, "failed to validate deploy plan")
 	}
 
+	// Pods fail to start until the ConfigMaps that they mount exist
+	if err = applyConfigMaps(ctx, plan); err != nil {
+		return nil, errors.Wrap(err, "failed to apply config maps")
+	}
+	if err = applyVolumeClaims(ctx, plan); err != nil {
+		return nil, errors.Wrap(err, "failed to apply volume claims")
+	}
+	if err = setAutoscaledReplicas(ctx, plan); err != nil {
+		return nil, errors.Wrap(err, "failed to get autoscaled replicas")
+	}
+
 	releases, err := executeDeployPlan(ctx, plan)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to execute deploy plan")
 	}
 
+	replicas, err := applyAutoscalers(ctx, plan)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply autoscalers")
+	}
+	dnsRecords, err := applyDomains(ctx, plan)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply domains")
+	}
+
 	return &DeployOutput{
 		InstanceName: plan.appChartConfig.instanceName,
 		Namespace:    plan.appChartConfig.Namespace,
 		Releases:     releases,
+		AdditionalApps: lo.Map(
+			plan.additionalAppChartConfigs,
+			func(cc *ChartConfig, _ int) string { return cc.releaseKey() },
+		),
+		Replicas:   replicas,
+		DNSRecords: dnsRecords,
 	}, nil
 }
 
@@ -171,54 +276,14 @@ func (p *Pad) makeDeployPlan(
 	ctx context.Context,
 	opts *DeployOptions,
 ) (*DeployPlan, error) {
-	envVars := map[string]string{}
-	for name, value := range opts.RemoteEnvVars {
-		envVars[name] = base64.StdEncoding.EncodeToString([]byte(value))
-	}
-
-	// if secrets are already set in helmOptions from env-override flag
-	// then merge them with secrets from parameter store with priority on env-override values
-	if _, ok := opts.App.Values["secrets"]; ok {
-		err := mergo.Merge(&envVars, opts.App.Values["secrets"], mergo.WithOverride)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to merge .env file values with jetpack env values")
-		}
-	}
-
 	secretsToMountAsFiles, err := loadSecretFiles(opts.SecretFilePaths)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load secret data from files: %v", opts.SecretFilePaths)
 	}
 
-	ttlSecondsAfterFinished := 86400 // 24 hours
-	if strings.EqualFold(opts.Environment, api.Environment_DEV.String()) {
-		ttlSecondsAfterFinished = 600 // 10 minutes, if dev
-	}
-
-	// Any value that is defaulted in helm/app/values.yaml should probably
-	// have strutil.NilIfEmpty() applied here. Otherwise, passing an empty string
-	// will remove the default.
-	appValues := goutil.FilterStringKeyMap(map[string]any{
-		"image": opts.App.Values["image"],
-		"jetpack": map[string]any{
-			"instanceName": opts.App.InstanceName,
-			"environment":  opts.Environment,
-			"sdkBinPath":   nil,
-		},
-		"serviceAccount": map[string]any{
-			"annotations": map[string]any{
-				"eks.amazonaws.com/role-arn": nil,
-			},
-		},
-		"secrets":               envVars, // store envVars using k8s secrets
-		"secretsToMountAsFiles": secretsToMountAsFiles,
-		"jobs": map[string]any{
-			"ttlSecondsAfterFinished": ttlSecondsAfterFinished,
-		},
-	})
-
-	if err := mergo.Merge(&appValues, opts.App.Values, mergo.WithAppendSlice); err != nil {
-		return nil, errors.Wrap(err, "unable to merge value maps")
+	appValues, err := makeAppValues(opts, opts.App, secretsToMountAsFiles)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	helmDriver := os.Getenv("HELM_DRIVER")
@@ -229,17 +294,68 @@ func (p *Pad) makeDeployPlan(
 
 	// chart config for user app
 	plan.appChartConfig = &ChartConfig{
+		autoscaling:   opts.App.Autoscaling,
 		chartLocation: opts.App.ChartLocation,
+		configMaps:    opts.App.ConfigMaps,
+		domains:       opts.App.Domains,
+		volumeClaims:  opts.App.PersistentVolumeClaims,
 		Name:          AppChartName,
-		chartVersion:  appChartVersion,
+		Version:       appChartVersion,
 		instanceName:  opts.App.InstanceName,
 		Release:       opts.App.ReleaseName,
 		Namespace:     opts.Namespace,
-		values:        appValues,
+		Values:        appValues,
 		Wait:          true,
 		Timeout:       goutil.Coalesce(opts.App.Timeout, defaultHelmTimeout),
 	}
 
+	// chart configs for additional web services, internal services and workers.
+	// These are releases of the same app chart, so they are keyed by instance
+	// name instead.
+	for _, app := range opts.AdditionalApps {
+		values, err := makeAppValues(opts, app, secretsToMountAsFiles)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		plan.additionalAppChartConfigs = append(plan.additionalAppChartConfigs, &ChartConfig{
+			autoscaling:   app.Autoscaling,
+			chartLocation: app.ChartLocation,
+			configMaps:    app.ConfigMaps,
+			domains:       app.Domains,
+			volumeClaims:  app.PersistentVolumeClaims,
+			Name:          AppChartName,
+			Version:       appChartVersion,
+			instanceName:  app.InstanceName,
+			key:           app.InstanceName,
+			Release:       app.ReleaseName,
+			Namespace:     opts.Namespace,
+			Values:        values,
+			Wait:          true,
+			Timeout:       goutil.Coalesce(app.Timeout, defaultHelmTimeout),
+		})
+	}
+
+	if opts.PreDeploy != nil {
+		values, err := makeAppValues(opts, opts.PreDeploy, secretsToMountAsFiles)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		plan.preDeployChartConfig = &ChartConfig{
+			chartLocation: opts.PreDeploy.ChartLocation,
+			configMaps:    opts.PreDeploy.ConfigMaps,
+			volumeClaims:  opts.PreDeploy.PersistentVolumeClaims,
+			Name:          AppChartName,
+			Version:       appChartVersion,
+			instanceName:  opts.PreDeploy.InstanceName,
+			key:           opts.PreDeploy.InstanceName,
+			Release:       opts.PreDeploy.ReleaseName,
+			Namespace:     opts.Namespace,
+			Values:        values,
+			Wait:          true,
+			Timeout:       goutil.Coalesce(opts.PreDeploy.Timeout, defaultHelmTimeout),
+		}
+	}
+
 	if opts.Runtime == nil {
 		// No need to install runtime chart.
 		return plan, nil
@@ -292,11 +408,11 @@ func (p *Pad) makeDeployPlan(
 	runtimeChartConfig := &ChartConfig{
 		chartLocation: opts.Runtime.ChartLocation,
 		Name:          RuntimeChartName,
-		chartVersion:  runtimeChartVersion,
+		Version:       runtimeChartVersion,
 		Release:       RuntimeChartName, // Not a mistake, name and install name are the same
 		instanceName:  RuntimeChartName, // Not a mistake, name and instance name are the same
 		Namespace:     opts.Namespace,
-		values:        runtimeValues,
+		Values:        runtimeValues,
 		Wait:          true,
 		Timeout:       goutil.Coalesce(opts.Runtime.Timeout, defaultHelmTimeout),
 	}
@@ -340,9 +456,9 @@ func (p *Pad) makeDeployPlan(
 		}
 
 		// WARNING: See comment in runtimeIsCurrent before adding more values here
-		runtimeChartConfig.values["redis"].(map[string]any)["password"] = redisPass
+		runtimeChartConfig.Values["redis"].(map[string]any)["password"] = redisPass
 		if apiKey, ok := secretData[ApiKeySecretName]; ok {
-			runtimeChartConfig.values["jetpack"].(map[string]any)["apiKeySecret"] =
+			runtimeChartConfig.Values["jetpack"].(map[string]any)["apiKeySecret"] =
 				base64.StdEncoding.EncodeToString(apiKey)
 		}
 		plan.runtimeChartConfig = runtimeChartConfig
@@ -352,6 +468,64 @@ func (p *Pad) makeDeployPlan(
 	return plan, nil
 }
 
+// makeAppValues merges the values computed for a single release of the app
+// chart with the values every release shares: secrets, mounted secret files
+// and job settings.
+func makeAppValues(
+	opts *DeployOptions,
+	app *HelmOptions,
+	secretsToMountAsFiles map[string]string,
+) (map[string]any, error) {
+	envVars := map[string]string{}
+	for name, value := range opts.RemoteEnvVars {
+		envVars[name] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	// if secrets are already set in helmOptions from env-override flag
+	// then merge them with secrets from parameter store with priority on env-override values
+	if _, ok := app.Values["secrets"]; ok {
+		err := mergo.Merge(&envVars, app.Values["secrets"], mergo.WithOverride)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to merge .env file values with jetpack env values")
+		}
+	}
+
+	ttlSecondsAfterFinished := int(opts.JobRetention.Seconds())
+	if ttlSecondsAfterFinished == 0 {
+		ttlSecondsAfterFinished = 86400 // 24 hours
+		if strings.EqualFold(opts.Environment, api.Environment_DEV.String()) {
+			ttlSecondsAfterFinished = 600 // 10 minutes, if dev
+		}
+	}
+
+	// Any value that is defaulted in helm/app/values.yaml should probably
+	// have strutil.NilIfEmpty() applied here. Otherwise, passing an empty string
+	// will remove the default.
+	appValues := goutil.FilterStringKeyMap(map[string]any{
+		"image": app.Values["image"],
+		"jetpack": map[string]any{
+			"instanceName": app.InstanceName,
+			"environment":  opts.Environment,
+			"sdkBinPath":   nil,
+		},
+		"serviceAccount": map[string]any{
+			"annotations": map[string]any{
+				"eks.amazonaws.com/role-arn": nil,
+			},
+		},
+		"secrets":               envVars, // store envVars using k8s secrets
+		"secretsToMountAsFiles": secretsToMountAsFiles,
+		"jobs": map[string]any{
+			"ttlSecondsAfterFinished": ttlSecondsAfterFinished,
+		},
+	})
+
+	if err := mergo.Merge(&appValues, app.Values, mergo.WithAppendSlice); err != nil {
+		return nil, errors.Wrap(err, "unable to merge value maps")
+	}
+	return appValues, nil
+}
+
 func validateDeployPlan(dp *DeployPlan) error {
 	for _, chart := range dp.Charts() {
 		if err := chart.validate(); err != nil {
@@ -489,7 +663,7 @@ func chartIsInstalledAndCurrent(
 		goutil.DigDelete(currentValues, path...)
 	}
 
-	return reflect.DeepEqual(cc.values, currentValues), nil
+	return reflect.DeepEqual(cc.Values, currentValues), nil
 }
 
 func getOrCreateRedisPass(secretData map[string][]byte) (string, error) {
@@ -0,0 +1,335 @@
+package launchpad
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Domains are the custom domains of an app chart release's web service, and
+// how they get TLS certificates.
+type Domains struct {
+	Hostnames []string
+	// Ambassador routes the domains with Ambassador Hosts and Mappings instead
+	// of an Ingress.
+	Ambassador       bool
+	IngressClassName string
+	// ACMEEmail is the email of the cert-manager Issuer that launchpad creates
+	// for the release, unless ClusterIssuer is set.
+	ACMEEmail     string
+	ClusterIssuer string
+	ServicePort   int
+	// Labels and Annotations of the resources, besides the ones launchpad sets.
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// DNSRecord is a record that must exist for a custom domain to reach the
+// cluster. Type and Value are empty if the address of the cluster's ingress
+// controller is not known yet.
+type DNSRecord struct {
+	Name  string
+	Type  string // A or CNAME
+	Value string
+}
+
+var (
+	ingressResource     = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	certificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	issuerResource      = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "issuers"}
+	hostResource        = schema.GroupVersionResource{Group: "getambassador.io", Version: "v3alpha1", Resource: "hosts"}
+)
+
+// domainResources are the kinds of resources that launchpad applies for
+// custom domains.
+var domainResources = []schema.GroupVersionResource{
+	ingressResource,
+	certificateResource,
+	issuerResource,
+	hostResource,
+	reaktor.AmbassadorMappingGVR(),
+}
+
+// domainResource is a resource to apply for the custom domains of a release.
+type domainResource struct {
+	gvr      schema.GroupVersionResource
+	name     string
+	resource reaktor.Resource
+}
+
+// applyDomains creates or updates the resources that route the custom domains
+// of each app release to its service, with a TLS certificate from
+// cert-manager. It deletes the ones that launchpad created for a release
+// before but that are no longer needed, e.g. because a domain was removed. It
+// returns the DNS records that the domains need.
+func applyDomains(ctx context.Context, plan *DeployPlan) ([]DNSRecord, error) {
+	rc, err := RESTConfigFromDefaults(plan.DeployOptions.KubeContext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s client rest config")
+	}
+	dynamicClient, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create k8s dynamic client")
+	}
+
+	records := []DNSRecord{}
+	apps := append([]*ChartConfig{plan.appChartConfig}, plan.additionalAppChartConfigs...)
+	for _, cc := range apps {
+		applied := map[string]bool{}
+		for _, r := range newDomainResources(cc) {
+			manifest, err := reaktor.ToManifest(r.resource)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			_, err = dynamicClient.
+				Resource(r.gvr).
+				Namespace(cc.Namespace).
+				Apply(ctx, r.name, manifest, metav1.ApplyOptions{
+					FieldManager: fieldManager,
+					Force:        true,
+				})
+			if k8sErrors.IsNotFound(err) {
+				// The cluster doesn't serve the resource's kind
+				return nil, errorutil.CombinedError(
+					err,
+					errorutil.NewUserErrorf(
+						"Domains of %s need %s, which is not installed in the cluster",
+						cc.instanceName,
+						lo.Ternary(r.gvr.Group == hostResource.Group, "Ambassador", "cert-manager"),
+					),
+				)
+			} else if err != nil {
+				return nil, errors.Wrapf(err, "failed to apply %s %s", r.gvr.Resource, r.name)
+			}
+			applied[r.gvr.Resource+"/"+r.name] = true
+		}
+
+		for _, gvr := range domainResources {
+			existing, err := dynamicClient.Resource(gvr).Namespace(cc.Namespace).List(
+				ctx,
+				metav1.ListOptions{LabelSelector: managedLabelSelector(cc.instanceName)},
+			)
+			if k8sErrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, errors.Wrapf(err, "failed to list %s of %s", gvr.Resource, cc.instanceName)
+			}
+			for _, item := range existing.Items {
+				if applied[gvr.Resource+"/"+item.GetName()] {
+					continue
+				}
+				err := dynamicClient.Resource(gvr).Namespace(cc.Namespace).Delete(
+					ctx,
+					item.GetName(),
+					metav1.DeleteOptions{},
+				)
+				if err != nil && !k8sErrors.IsNotFound(err) {
+					return nil, errors.Wrapf(err, "failed to delete %s %s", gvr.Resource, item.GetName())
+				}
+			}
+		}
+
+		if cc.domains != nil {
+			address, err := ingressAddress(ctx, rc, cc)
+			if err != nil {
+				return nil, err
+			}
+			for _, hostname := range cc.domains.Hostnames {
+				records = append(records, newDNSRecord(hostname, address))
+			}
+		}
+	}
+	return records, nil
+}
+
+// newDomainResources returns the resources that route the custom domains of a
+// release to its service: an Ingress, or an Ambassador Host and Mapping for
+// each domain, and a certificate for the domains from a cert-manager Issuer.
+func newDomainResources(cc *ChartConfig) []*domainResource {
+	if cc.domains == nil || len(cc.domains.Hostnames) == 0 {
+		return nil
+	}
+	d := cc.domains
+	name := AppServiceName(cc.instanceName)
+	labels := lo.Assign(d.Labels, managedLabels(cc.instanceName))
+	tlsSecretName := name + "-tls"
+	resources := []*domainResource{}
+
+	issuerName, issuerKind := d.ClusterIssuer, "ClusterIssuer"
+	if d.ClusterIssuer == "" {
+		issuerName, issuerKind = name, "Issuer"
+		resources = append(resources, &domainResource{
+			gvr:  issuerResource,
+			name: issuerName,
+			resource: &komponents.Issuer{
+				Name:             issuerName,
+				Namespace:        cc.Namespace,
+				Labels:           labels,
+				Annotations:      d.Annotations,
+				ACMEEmail:        d.ACMEEmail,
+				IngressClassName: d.IngressClassName,
+			},
+		})
+	}
+	resources = append(resources, &domainResource{
+		gvr:  certificateResource,
+		name: name,
+		resource: &komponents.Certificate{
+			Name:        name,
+			Namespace:   cc.Namespace,
+			Labels:      labels,
+			Annotations: d.Annotations,
+			DNSNames:    d.Hostnames,
+			SecretName:  tlsSecretName,
+			IssuerName:  issuerName,
+			IssuerKind:  issuerKind,
+		},
+	})
+
+	if !d.Ambassador {
+		return append(resources, &domainResource{
+			gvr:  ingressResource,
+			name: name,
+			resource: &komponents.Ingress{
+				Name:          name,
+				Namespace:     cc.Namespace,
+				Labels:        labels,
+				Annotations:   d.Annotations,
+				ClassName:     d.IngressClassName,
+				Hostnames:     d.Hostnames,
+				ServiceName:   name,
+				ServicePort:   d.ServicePort,
+				TLSSecretName: tlsSecretName,
+			},
+		})
+	}
+	for _, hostname := range d.Hostnames {
+		hostName := name + "-" + strings.ReplaceAll(hostname, ".", "-")
+		resources = append(
+			resources,
+			&domainResource{
+				gvr:  hostResource,
+				name: hostName,
+				resource: &komponents.Host{
+					Name:          hostName,
+					Hostname:      hostname,
+					Namespace:     cc.Namespace,
+					Labels:        labels,
+					Annotations:   d.Annotations,
+					TLSSecretName: tlsSecretName,
+				},
+			},
+			&domainResource{
+				gvr:  reaktor.AmbassadorMappingGVR(),
+				name: hostName,
+				resource: &komponents.Mapping{
+					Name:        hostName,
+					Namespace:   cc.Namespace,
+					Labels:      labels,
+					Annotations: d.Annotations,
+					Hostname:    hostname,
+					Service:     fmt.Sprintf("%s.%s:%d", name, cc.Namespace, d.ServicePort),
+				},
+			},
+		)
+	}
+	return resources
+}
+
+// ingressAddress returns the external IP or hostname that the release's
+// domains must point to, or "" if the load balancer doesn't have one yet.
+func ingressAddress(ctx context.Context, rc *rest.Config, cc *ChartConfig) (string, error) {
+	clientset, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create k8s clientset")
+	}
+
+	if !cc.domains.Ambassador {
+		ing, err := clientset.NetworkingV1().Ingresses(cc.Namespace).Get(
+			ctx,
+			AppServiceName(cc.instanceName),
+			metav1.GetOptions{},
+		)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get ingress")
+		}
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			if address := goutil.Coalesce(lb.Hostname, lb.IP); address != "" {
+				return address, nil
+			}
+		}
+		return "", nil
+	}
+
+	// Ambassador's own load balancer service, in whichever namespace it runs
+	svcs, err := clientset.CoreV1().Services(metav1.NamespaceAll).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: "app.kubernetes.io/name in (ambassador,emissary-ingress)",
+		},
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list ambassador services")
+	}
+	for _, svc := range svcs.Items {
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			if address := goutil.Coalesce(lb.Hostname, lb.IP); address != "" {
+				return address, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// newDNSRecord returns the record that points hostname to address: an A
+// record for an IP address, or a CNAME for a hostname.
+func newDNSRecord(hostname, address string) DNSRecord {
+	record := DNSRecord{Name: hostname, Value: address}
+	if address == "" {
+		return record
+	}
+	record.Type = lo.Ternary(net.ParseIP(address) != nil, "A", "CNAME")
+	return record
+}
+
+// deleteDomains deletes the resources that launchpad applied for the custom
+// domains of the instances that selector matches.
+func deleteDomains(ctx context.Context, rc *rest.Config, namespace, selector string) error {
+	dynamicClient, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s dynamic client")
+	}
+	for _, gvr := range domainResources {
+		err := dynamicClient.Resource(gvr).Namespace(namespace).DeleteCollection(
+			ctx,
+			metav1.DeleteOptions{},
+			metav1.ListOptions{
+				LabelSelector: selector + ",app.kubernetes.io/managed-by=" + fieldManager,
+			},
+		)
+		// NotFound means the cluster doesn't serve the kind, e.g. without
+		// cert-manager
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s for ns %s", gvr.Resource, namespace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package launchpad
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewDomainResources(t *testing.T) {
+	cc := &ChartConfig{
+		instanceName: "my-app-web",
+		Namespace:    "my-ns",
+		domains: &Domains{
+			Hostnames:        []string{"example.com", "www.example.com"},
+			IngressClassName: "nginx",
+			ACMEEmail:        "ops@example.com",
+			ServicePort:      8080,
+			Labels:           map[string]string{"team": "payments"},
+		},
+	}
+	manifests := func() []*unstructured.Unstructured {
+		return lo.Map(newDomainResources(cc), func(r *domainResource, _ int) *unstructured.Unstructured {
+			manifest, err := reaktor.ToManifest(r.resource)
+			assert.NoError(t, err)
+			assert.Equal(t, r.name, manifest.GetName())
+			assert.Equal(t, "my-ns", manifest.GetNamespace())
+			assert.Equal(t, "my-app-web", manifest.GetLabels()["app.kubernetes.io/instance"])
+			assert.Equal(t, "payments", manifest.GetLabels()["team"])
+			return manifest
+		})
+	}
+
+	resources := manifests()
+	assert.Equal(
+		t,
+		[]string{"Issuer", "Certificate", "Ingress"},
+		lo.Map(resources, func(m *unstructured.Unstructured, _ int) string { return m.GetKind() }),
+	)
+	email, _, _ := unstructured.NestedString(resources[0].Object, "spec", "acme", "email")
+	assert.Equal(t, "ops@example.com", email)
+	issuer, _, _ := unstructured.NestedString(resources[1].Object, "spec", "issuerRef", "name")
+	assert.Equal(t, "my-app-web-app", issuer)
+	secret, _, _ := unstructured.NestedString(resources[1].Object, "spec", "secretName")
+	assert.Equal(t, "my-app-web-app-tls", secret)
+	class, _, _ := unstructured.NestedString(resources[2].Object, "spec", "ingressClassName")
+	assert.Equal(t, "nginx", class)
+	rules, _, _ := unstructured.NestedSlice(resources[2].Object, "spec", "rules")
+	assert.Len(t, rules, 2)
+	assert.Equal(t, "www.example.com", rules[1].(map[string]any)["host"])
+	paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]any), "http", "paths")
+	backend, _, _ := unstructured.NestedMap(paths[0].(map[string]any), "backend", "service")
+	assert.Equal(
+		t,
+		map[string]any{"name": "my-app-web-app", "port": map[string]any{"number": int64(8080)}},
+		backend,
+	)
+
+	cc.domains.Ambassador = true
+	cc.domains.ClusterIssuer = "letsencrypt"
+	resources = manifests()
+	assert.Equal(
+		t,
+		[]string{"Certificate", "Host", "Mapping", "Host", "Mapping"},
+		lo.Map(resources, func(m *unstructured.Unstructured, _ int) string { return m.GetKind() }),
+	)
+	kind, _, _ := unstructured.NestedString(resources[0].Object, "spec", "issuerRef", "kind")
+	assert.Equal(t, "ClusterIssuer", kind)
+	assert.Equal(t, "my-app-web-app-www-example-com", resources[3].GetName())
+	tlsSecret, _, _ := unstructured.NestedString(resources[3].Object, "spec", "tlsSecret", "name")
+	assert.Equal(t, "my-app-web-app-tls", tlsSecret)
+	target, _, _ := unstructured.NestedString(resources[4].Object, "spec", "service")
+	assert.Equal(t, "my-app-web-app.my-ns:8080", target)
+
+	cc.domains = nil
+	assert.Empty(t, newDomainResources(cc))
+}
+
+func TestNewDNSRecord(t *testing.T) {
+	assert.Equal(
+		t,
+		DNSRecord{Name: "example.com", Type: "A", Value: "203.0.113.7"},
+		newDNSRecord("example.com", "203.0.113.7"),
+	)
+	assert.Equal(
+		t,
+		DNSRecord{Name: "example.com", Type: "CNAME", Value: "lb.example.net"},
+		newDNSRecord("example.com", "lb.example.net"),
+	)
+	assert.Equal(t, DNSRecord{Name: "example.com"}, newDNSRecord("example.com", ""))
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/samber/lo"
 	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/pkg/jetlog"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -14,11 +17,18 @@ import (
 )
 
 type DownOptions struct {
+	// AdditionalApps are the extra app chart releases, one per web service
+	// beyond the first, one per internal service or worker, and the one for
+	// pre-deploy jobs. Only ReleaseName and InstanceName are used.
+	AdditionalApps []*HelmOptions
 	ExternalCharts []*ChartConfig
 	ReleaseName    string
 	InstanceName   string
 	Namespace      string
 	KubeContext    string
+	// DeleteVolumes deletes the PersistentVolumeClaims of the app, and with
+	// them the data in its volumes. They are kept otherwise.
+	DeleteVolumes bool
 }
 
 type helmRelease struct {
@@ -77,12 +87,25 @@ func makeDownPlan(ctx context.Context, opts *DownOptions) (*downPlan, error) {
 		return nil, errorutil.CombinedError(err, errUnableToAccessHelmReleases)
 	}
 
+	additionalApps := lo.SliceToMap(
+		opts.AdditionalApps,
+		func(app *HelmOptions) (string, *HelmOptions) { return app.ReleaseName, app },
+	)
+
 	appsInstalled := 0
 	appFound := false
 	runtimeFound := false
 	for _, r := range releases {
 		if r.Name == RuntimeChartName {
 			runtimeFound = true
+		} else if app, ok := additionalApps[r.Name]; ok {
+			// Additional web services, internal services and workers belong to
+			// this app, so they don't count as other apps sharing the runtime.
+			plan.releases = append(plan.releases, helmRelease{
+				ReleaseName:  app.ReleaseName,
+				InstanceName: app.InstanceName,
+				Namespace:    opts.Namespace,
+			})
 		} else {
 			appsInstalled++
 			if r.Name == opts.ReleaseName {
@@ -142,12 +165,19 @@ func deleteChildResources(ctx context.Context, plan *downPlan) error {
 		return errors.Wrap(err, "failed to create k8s clientset")
 	}
 
+	instanceNames := append(
+		[]string{plan.downOptions.InstanceName},
+		lo.Map(plan.downOptions.AdditionalApps, func(app *HelmOptions, _ int) string {
+			return app.InstanceName
+		})...,
+	)
+
 	// For now, delete using the typed API for the handful of resource types that
 	// we know we create. As that set expands, rewrite to use untyped API.
 	selector := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf(
-			"app.kubernetes.io/instance=%s",
-			plan.downOptions.InstanceName,
+			"app.kubernetes.io/instance in (%s)",
+			strings.Join(instanceNames, ","),
 		),
 	}
 	namespace := plan.downOptions.Namespace
@@ -175,5 +205,71 @@ func deleteChildResources(ctx context.Context, plan *downPlan) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete cronjobs for ns %s", namespace)
 	}
+	// Autoscalers are applied after the app releases, so helm doesn't know them
+	err = clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).DeleteCollection(
+		ctx,
+		metav1.DeleteOptions{},
+		selector,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete autoscalers for ns %s", namespace)
+	}
+	// Config maps that launchpad applies itself, e.g. for config files. The
+	// ones in app releases are deleted by helm.
+	err = clientset.CoreV1().ConfigMaps(namespace).DeleteCollection(
+		ctx,
+		metav1.DeleteOptions{},
+		metav1.ListOptions{
+			LabelSelector: selector.LabelSelector +
+				",app.kubernetes.io/managed-by=" + fieldManager,
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete config maps for ns %s", namespace)
+	}
+	// Resources for custom domains are applied after the app releases too
+	if err = deleteDomains(ctx, rc, namespace, selector.LabelSelector); err != nil {
+		return err
+	}
+	return errors.WithStack(deleteVolumeClaims(ctx, plan, clientset, selector.LabelSelector))
+}
+
+// deleteVolumeClaims deletes the claims of the app's volumes if the user asked
+// to, or tells them which ones were kept otherwise.
+func deleteVolumeClaims(
+	ctx context.Context,
+	plan *downPlan,
+	clientset kubernetes.Interface,
+	instanceSelector string,
+) error {
+	namespace := plan.downOptions.Namespace
+	selector := metav1.ListOptions{
+		LabelSelector: instanceSelector + ",app.kubernetes.io/managed-by=" + fieldManager,
+	}
+	if plan.downOptions.DeleteVolumes {
+		err := clientset.CoreV1().PersistentVolumeClaims(namespace).DeleteCollection(
+			ctx,
+			metav1.DeleteOptions{},
+			selector,
+		)
+		return errors.Wrapf(err, "failed to delete volume claims for ns %s", namespace)
+	}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, selector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list volume claims for ns %s", namespace)
+	}
+	if len(pvcs.Items) > 0 {
+		jetlog.Logger(ctx).Printf(
+			"Kept volumes %s, so their data is still there if you deploy again. "+
+				"Run `launchpad down --delete-volumes` to delete them.\n",
+			strings.Join(
+				lo.Map(pvcs.Items, func(pvc corev1.PersistentVolumeClaim, _ int) string {
+					return pvc.Name
+				}),
+				", ",
+			),
+		)
+	}
 	return nil
 }
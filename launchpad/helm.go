@@ -15,6 +15,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -51,10 +52,10 @@ func applyHelmCharts(
 
 		r := findRelease(currentReleases, cc.Release)
 		if r != nil {
-			releases[cc.Name], err = upgradeHelmChart(ctx, cc, settings, c)
+			releases[cc.releaseKey()], err = upgradeHelmChart(ctx, cc, settings, c)
 
 			if plan.DeployOptions.ReinstallOnHelmUpgradeError {
-				releases[cc.Name], err = reinstallHelmChart(ctx, cc.Release, cc, settings, c, createNamespace)
+				releases[cc.releaseKey()], err = reinstallHelmChart(ctx, cc.Release, cc, settings, c, createNamespace)
 				if err != nil {
 					return nil, errors.WithStack(err)
 				}
@@ -72,17 +73,24 @@ func applyHelmCharts(
 				// We will automatically down the old release and up a new release.
 				// Since the old release is using app name as the release name.
 				jetlog.Logger(ctx).IndentedPrintln("Detected old install by the project name. Changing to install by project ID.")
-				releases[cc.Name], err = reinstallHelmChart(ctx, cc.instanceName, cc, settings, c, createNamespace)
+				releases[cc.releaseKey()], err = reinstallHelmChart(ctx, cc.instanceName, cc, settings, c, createNamespace)
 				if err != nil {
 					return nil, errors.WithStack(err)
 				}
 			} else {
-				releases[cc.Name], err = installHelmChart(ctx, cc, settings, c, createNamespace)
+				releases[cc.releaseKey()], err = installHelmChart(ctx, cc, settings, c, createNamespace)
 				if err != nil {
 					return releases, errors.Wrap(err, "Error installing helm chart")
 				}
 			}
 		}
+
+		if cc == plan.preDeployChartConfig {
+			err := waitForPreDeployJobs(ctx, plan, releases[cc.releaseKey()])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
 	}
 
 	for _, chart := range plan.DeployOptions.ExternalCharts {
@@ -90,14 +98,34 @@ func applyHelmCharts(
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		r := findRelease(currentReleases, chart.Release)
+		chartReleases := currentReleases
+		if chart.Namespace != plan.DeployOptions.Namespace {
+			chartReleases, err = listReleases(
+				ctx,
+				plan.helmDriver,
+				plan.DeployOptions.KubeContext,
+				chart.Namespace,
+			)
+			if err != nil {
+				return nil, errorutil.CombinedError(err, errUnableToAccessHelmReleases)
+			}
+		}
+		r := findRelease(chartReleases, chart.Release)
 		if r != nil {
 			_, err = upgradeHelmChart(ctx, chart, settings, c)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			_, err = installHelmChart(ctx, chart, settings, c, createNamespace)
+			// A namespace picked for the chart in launchpad.yaml is created if
+			// it doesn't exist.
+			_, err = installHelmChart(
+				ctx,
+				chart,
+				settings,
+				c,
+				createNamespace || chart.Namespace != plan.DeployOptions.Namespace,
+			)
 			if err != nil {
 				return nil, err
 			}
@@ -120,6 +148,9 @@ func installHelmChart(
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading chart")
 	}
+	if err := checkAppChartValues(chart, cc); err != nil {
+		return nil, errors.WithStack(err)
+	}
 
 	install.Namespace = cc.Namespace
 	install.ReleaseName = cc.Release
@@ -133,7 +164,7 @@ func installHelmChart(
 	install.Timeout = cc.Timeout
 
 	jetlog.Logger(ctx).BoldPrintf("Installing %s...\n", cc.HumanName())
-	rel, err := install.RunWithContext(ctx, chart, cc.values)
+	rel, err := install.RunWithContext(ctx, chart, cc.Values)
 	if err != nil {
 		return rel, errors.Wrap(err, "Error installing helm chart")
 	}
@@ -153,6 +184,9 @@ func upgradeHelmChart(
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading chart")
 	}
+	if err := checkAppChartValues(chart, cc); err != nil {
+		return nil, errors.WithStack(err)
+	}
 
 	upgrade.Namespace = cc.Namespace
 	upgrade.Wait = cc.Wait
@@ -160,7 +194,7 @@ func upgradeHelmChart(
 	upgrade.MaxHistory = 10 // 10 is the CLI default
 
 	jetlog.Logger(ctx).BoldPrintf("Upgrading %s...\n", cc.HumanName())
-	rel, err := upgrade.RunWithContext(ctx, cc.Release, chart, cc.values)
+	rel, err := upgrade.RunWithContext(ctx, cc.Release, chart, cc.Values)
 	if err != nil {
 		return rel, errors.Wrap(err, "Error upgrading helm chart")
 	}
@@ -200,12 +234,24 @@ func uninstallChart(
 	}
 
 	for _, chart := range downPlan.downOptions.ExternalCharts {
-
-		uninstall := action.NewUninstall(config)
+		chartConfig, err := actionConfig(ctx, downPlan.helmDriver, chart.Namespace, settings)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		uninstall := action.NewUninstall(chartConfig)
+		uninstall.Wait = chart.Wait
+		uninstall.Timeout = chart.Timeout
 
 		jetlog.Logger(ctx).BoldPrintf("Uninstalling %s...\n", chart.Release)
 		_, err = uninstall.Run(chart.Release)
-		if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			jetlog.Logger(ctx).IndentedPrintf(
+				"%s is not installed in namespace %s\n",
+				chart.Release,
+				chart.Namespace,
+			)
+			continue
+		} else if err != nil {
 			return errors.Wrapf(err, "failed to uninstall release-name: %s", chart.Release)
 		}
 
@@ -226,13 +272,17 @@ func getChart(
 	}
 
 	var err error
-	chartURL := fmt.Sprintf("%s/%s-%s.tgz", repoURL, cc.Name, cc.chartVersion)
+	chartURL := fmt.Sprintf("%s/%s-%s.tgz", repoURL, cc.Name, cc.Version)
 	if cc.Repo != "" {
 		chartURL, err = repo.FindChartInRepoURL(
-			cc.Repo, cc.Name, "", "", "", "", getter.All(settings))
+			cc.Repo, cc.Name, cc.Version, "", "", "", getter.All(settings))
 		if err != nil {
 			return nil, errors.Wrap(err, "Error finding chart in repo")
 		}
+	} else if registry.IsOCI(cc.Name) {
+		// The registry client resolves the version when pulling the chart
+		chartURL = cc.Name
+		cpo.Version = cc.Version
 	}
 
 	cp, err := cpo.LocateChart(chartURL, settings)
@@ -351,7 +401,13 @@ func actionConfig(
 	settings *cli.EnvSettings,
 ) (*action.Configuration, error) {
 
-	cfg := &action.Configuration{}
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating registry client")
+	}
+	cfg := &action.Configuration{RegistryClient: registryClient}
 	if err := cfg.Init(
 		settings.RESTClientGetter(),
 		namespace,
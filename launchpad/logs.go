@@ -87,6 +87,12 @@ func tailLogsForApp(
 ) error {
 	podLabels := getPodLabelForApp(name, revision)
 	includeRegexp := []*regexp.Regexp{}
+	containerStates := []stern.ContainerState{containerState}
+	if containerState == stern.RUNNING {
+		// Init containers have already terminated by the time the service's
+		// containers are running.
+		containerStates = append(containerStates, stern.TERMINATED)
+	}
 	err := tailLogsImpl(
 		ctx,
 		kubeCtx,
@@ -94,7 +100,7 @@ func tailLogsForApp(
 		podLabels,
 		includeRegexp,
 		time.Hour,
-		[]stern.ContainerState{containerState},
+		containerStates,
 	)
 	return errors.WithStack(err)
 }
@@ -1,11 +1,27 @@
 package launchpad
 
-import "time"
+import (
+	"time"
+
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
+)
 
 type HelmOptions struct {
+	// Autoscaling is only used by app chart releases. Nil means a fixed number
+	// of replicas.
+	Autoscaling   *Autoscaling
 	ChartLocation string
-	InstanceName  string // display name for helm install
-	ReleaseName   string // app identifier for helm install
-	Timeout       time.Duration
-	Values        map[string]any
+	// ConfigMaps are applied before the release is installed, e.g. to hold the
+	// config files of its services. Only used by app chart releases.
+	ConfigMaps []*komponents.ConfigMap
+	// Domains are the custom domains of the release's web service, if any. Only
+	// used by app chart releases.
+	Domains      *Domains
+	InstanceName string // display name for helm install
+	// PersistentVolumeClaims are applied like ConfigMaps, but deploys never
+	// delete them. Only used by app chart releases.
+	PersistentVolumeClaims []*komponents.PersistentVolumeClaim
+	ReleaseName            string // app identifier for helm install
+	Timeout                time.Duration
+	Values                 map[string]any
 }
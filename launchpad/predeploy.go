@@ -0,0 +1,120 @@
+package launchpad
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stern/stern/stern"
+	"go.jetpack.io/launchpad/goutil"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/pkg/jetlog"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"go.jetpack.io/launchpad/pkg/reaktor/kubeconfig"
+	"go.jetpack.io/launchpad/pkg/reaktor/kubejobs"
+	"golang.org/x/exp/maps"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
+)
+
+// waitForPreDeployJobs streams the logs of the jobs in the pre-deploy release
+// and waits for them to finish. If any of them fails, the deploy is aborted
+// so that the services keep running the previous version.
+func waitForPreDeployJobs(
+	ctx context.Context,
+	plan *DeployPlan,
+	rel *release.Release,
+) error {
+	if rel == nil {
+		return errors.WithStack(errNoDeployRelease)
+	}
+	cc := plan.preDeployChartConfig
+	jobs, err := jobsInManifest(rel.Manifest)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	kubeCtx := plan.DeployOptions.KubeContext
+	logCtx, stopLogs := context.WithCancel(ctx)
+	defer stopLogs()
+	err = tailLogsImpl(
+		logCtx,
+		kubeCtx,
+		cc.Namespace,
+		getPodLabelForApp(cc.instanceName, rel.Version),
+		nil, // includeRegexp
+		time.Hour,
+		[]stern.ContainerState{stern.RUNNING, stern.TERMINATED},
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	klient, err := reaktor.WithClientBuilder(
+		kubeconfig.NewClientBuilder(kubeconfig.WithFlags(&kubeconfig.Flags{
+			Context: kubeCtx,
+		})),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, goutil.Coalesce(cc.Timeout, defaultHelmTimeout))
+	defer cancel()
+	for _, job := range jobs {
+		jetlog.Logger(ctx).Printf("Waiting for pre-deploy job %s to finish\n", job.GetName())
+		job.SetNamespace(cc.Namespace)
+		evt, err := klient.WatchUntil(ctx, job, func(e watch.Event) (bool, error) {
+			return kubejobs.IsJobFinished(e)
+		})
+		if err != nil {
+			return errorutil.CombinedError(
+				errors.Wrapf(err, "failed to wait for pre-deploy job %s", job.GetName()),
+				errorutil.NewUserErrorf(
+					"Pre-deploy job %s did not finish. The deploy was aborted and the "+
+						"previous version is still running.",
+					job.GetName(),
+				),
+			)
+		}
+		failed, err := kubejobs.IsJobFailedWithReason(evt)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if failed.IsFailed {
+			return errorutil.NewUserErrorf(
+				"Pre-deploy job %s failed (%s). The deploy was aborted and the "+
+					"previous version is still running.",
+				job.GetName(),
+				goutil.Coalesce(failed.Reason, "unknown reason"),
+			)
+		}
+	}
+	return nil
+}
+
+// jobsInManifest returns the Jobs in a release's manifest, in the order that
+// helm installs them.
+func jobsInManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	jobs := []*unstructured.Unstructured{}
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := maps.Keys(manifests)
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	for _, key := range keys {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(manifests[key]), &obj.Object); err != nil {
+			return nil, errors.Wrap(err, "failed to parse release manifest")
+		}
+		if obj.GetAPIVersion() == "batch/v1" && obj.GetKind() == "Job" {
+			jobs = append(jobs, obj)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,46 @@
+package launchpad
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestJobsInManifest(t *testing.T) {
+	manifest := `---
+# Source: app/templates/service-account.yaml
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-app-pre-deploy
+---
+# Source: app/templates/jobs.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: my-app-migrate
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+---
+# Source: app/templates/jobs.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: my-app-seed
+`
+	jobs, err := jobsInManifest(manifest)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{"my-app-migrate", "my-app-seed"},
+		lo.Map(jobs, func(j *unstructured.Unstructured, _ int) string { return j.GetName() }),
+	)
+
+	jobs, err = jobsInManifest("")
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
@@ -0,0 +1,67 @@
+package launchpad
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var volumeClaimResource = schema.GroupVersionResource{
+	Version:  "v1",
+	Resource: "persistentvolumeclaims",
+}
+
+// applyVolumeClaims creates the PersistentVolumeClaims of each app release, or
+// updates them, e.g. to grow a volume. Claims of volumes that were removed
+// from launchpad.yaml are kept, so that no data is lost. `launchpad down
+// --delete-volumes` deletes them.
+func applyVolumeClaims(ctx context.Context, plan *DeployPlan) error {
+	rc, err := RESTConfigFromDefaults(plan.DeployOptions.KubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client rest config")
+	}
+	dynamicClient, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s dynamic client")
+	}
+
+	for _, cc := range plan.appCharts() {
+		for _, pvc := range cc.volumeClaims {
+			pvc.Namespace = cc.Namespace
+			pvc.Labels = lo.Assign(pvc.Labels, managedLabels(cc.instanceName))
+			manifest, err := reaktor.ToManifest(pvc)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = dynamicClient.
+				Resource(volumeClaimResource).
+				Namespace(cc.Namespace).
+				Apply(ctx, pvc.Name, manifest, metav1.ApplyOptions{
+					FieldManager: fieldManager,
+					Force:        true,
+				})
+			if k8sErrors.IsInvalid(err) {
+				// e.g. shrinking a volume, or changing its storage class
+				return errorutil.CombinedError(
+					err,
+					errorutil.NewUserErrorf(
+						"Volume %s can't be changed that way. Volumes can only grow, and "+
+							"only if their storage class allows it. To start over, run "+
+							"`launchpad down --delete-volumes`, which deletes their data",
+						pvc.Name,
+					),
+				)
+			} else if err != nil {
+				return errors.Wrapf(err, "failed to apply volume claim %s", pvc.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -157,7 +157,7 @@ func makeBuildOptions(
 		Services:          jetCfg.Builders(),
 		RemoteCache:       opts.RemoteCache,
 		RepoConfig:        repoConfig,
-		TagPrefix:         cmdOpts.RootFlags().Env().ImageTagPrefix(),
+		TagPrefix:         jetCfg.ImageTagPrefix(),
 	}
 
 	return buildOpts, nil
@@ -169,7 +169,7 @@ func cleanupPreviousBuildsPostRun(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	jetCfg, err := jetconfig.RequireFromFileSystem(ctx, absPath, cmdOpts.RootFlags().Env())
+	jetCfg, err := jetconfig.RequireFromFileSystem(ctx, absPath, cmdOpts.RootFlags().EnvName())
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -2,34 +2,63 @@ package command
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 
 	"go.jetpack.io/envsec"
+	"go.jetpack.io/launchpad/goutil"
+	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/launchpad"
 	"go.jetpack.io/launchpad/padcli/helm"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
+	"go.jetpack.io/launchpad/padcli/provider"
 )
 
+// jetconfigHelmToChartConfig returns the charts of the project's helm
+// services. Charts without a namespace of their own are installed in ns.
+// Values are not set, see helmChartValues.
 func jetconfigHelmToChartConfig(
 	jetCfg *jetconfig.Config,
-	ns string, // Remove this since all charts are in the same namespace.
+	ns string,
 ) []*launchpad.ChartConfig {
 	return lo.Map(
 		jetCfg.HelmCharts(),
 		func(hc jetconfig.HelmChart, _ int) *launchpad.ChartConfig {
 			return &launchpad.ChartConfig{
 				Repo:      hc.GetRepo(),
-				Name:      hc.GetName(),
-				Namespace: ns,
-				Release:   helm.ToValidName(hc.GetName() + "-" + jetCfg.GetProjectSlug()),
-				Wait:      false, // we have no idea what's in here. It might be slow
+				Name:      hc.GetChart(),
+				Namespace: goutil.Coalesce(hc.GetNamespace(), ns),
+				Release: goutil.Coalesce(
+					hc.GetReleaseName(),
+					helm.ToValidName(hc.GetName()+"-"+jetCfg.GetProjectSlug()),
+				),
+				Version: hc.GetVersion(),
+				Timeout: hc.GetTimeout(),
+				// Off by default since we have no idea what's in here. It might be slow
+				Wait: hc.GetWait(),
 			}
 		},
 	)
 }
 
+// helmChartValues returns the values of a helm service: its values files,
+// relative to launchpad.yaml, merged in order, and then its inline values.
+func helmChartValues(hc jetconfig.HelmChart) (map[string]any, error) {
+	values, err := helm.MergeValuesFiles(
+		lo.Map(hc.GetValuesFiles(), func(f string, _ int) string {
+			return filepath.Join(hc.GetPath(), f)
+		}),
+		hc.GetValues(),
+	)
+	return values, errorutil.CombinedError(
+		err,
+		errorutil.NewUserErrorf("Failed to read valuesFiles of helm service %s", hc.GetName()),
+	)
+}
+
 func getRemoteEnvVars(
 	ctx context.Context,
 	jetCfg *jetconfig.Config,
@@ -41,7 +70,11 @@ func getRemoteEnvVars(
 		return envVars, nil
 	}
 	// load secrets from parameter store
-	envID, err := cmdOpts.EnvSecProvider().NewEnvId(ctx, jetCfg.GetProjectID(), cmdOpts.RootFlags().Env().String())
+	envID, err := cmdOpts.EnvSecProvider().NewEnvId(
+		ctx,
+		jetCfg.GetProjectID(),
+		strings.ToUpper(jetCfg.SelectedEnvironment()),
+	)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -55,3 +88,28 @@ func getRemoteEnvVars(
 	}
 	return envVars, nil
 }
+
+// getNamespace returns the namespace to deploy to. The --namespace flag takes
+// precedence over the namespace configured for the environment in
+// launchpad.yaml. If neither is set, the namespace provider picks one.
+func getNamespace(
+	ctx context.Context,
+	jetCfg *jetconfig.Config,
+	nsFlag string,
+	cluster provider.Cluster,
+) (string, error) {
+	ns := nsFlag
+	if ns == "" {
+		var err error
+		if ns, err = jetCfg.Namespace(); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	ns, err := cmdOpts.NamespaceProvider().Get(
+		ctx,
+		ns,
+		cluster.GetKubeContext(),
+		cmdOpts.RootFlags().EnvName(),
+	)
+	return ns, errors.WithStack(err)
+}
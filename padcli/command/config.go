@@ -2,11 +2,14 @@ package command
 
 import (
 	"context"
-	"strings"
+	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
 	"go.jetpack.io/launchpad/padcli/provider"
 	"go.jetpack.io/launchpad/pkg/jetlog"
@@ -23,17 +26,54 @@ func configCmd() *cobra.Command {
 		},
 	}
 
+	var dryRun bool
 	upgradeCmd := &cobra.Command{
 		Use:   "upgrade [path]",
 		Short: "Upgrades a project's launchpad.yaml to follow the latest schema",
 		Long: "Upgrades a project's launchpad.yaml to follow the latest schema found " +
-			"at https://www.jetpack.io/launchpad/docs/reference/launchpad.yaml-reference/",
+			"at https://www.jetpack.io/launchpad/docs/reference/launchpad.yaml-reference/. " +
+			"Comments are kept. Use --dry-run to print the changes as a unified diff " +
+			"without writing them.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			jetlog.Logger(ctx).HeaderPrintf("Step 1/1 checking if the jetconfig needs to upgrade.\n")
 
-			// This calls upgrade internally.
-			_, err := RequireConfigFromFileSystem(ctx, cmd, args, cmdOpts)
+			p, err := absPath(args)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			upgrade, err := jetconfig.PlanUpgrade(p)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			configFile := displayConfigPath(p)
+			if !upgrade.NeedsUpgrade() {
+				jetlog.Logger(ctx).Printf("%s is already the latest version\n", configFile)
+			} else if dryRun {
+				diff, err := upgrade.Diff()
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				_, err = fmt.Fprint(cmd.OutOrStdout(), diff)
+				return errors.WithStack(err)
+			} else {
+				if err := upgrade.Write(); err != nil {
+					return errors.WithStack(err)
+				}
+				jetlog.Logger(ctx).Printf(
+					"Upgraded %s from version %s to %s\n",
+					configFile,
+					upgrade.FromVersion,
+					upgrade.ToVersion,
+				)
+				for _, m := range upgrade.Migrations {
+					jetlog.Logger(ctx).Printf("  - %s\n", m)
+				}
+			}
+
+			// Make sure the upgraded config loads
+			_, err = RequireConfigFromFileSystem(ctx, cmd, args, cmdOpts)
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -42,11 +82,165 @@ func configCmd() *cobra.Command {
 			return nil
 		},
 	}
-	configCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Print the changes as a unified diff instead of writing them",
+	)
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validates a project's launchpad.yaml",
+		Long: "Validates a project's launchpad.yaml and reports every problem found, " +
+			"with its line and column. Exits with a non-zero status if any problem is found.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p, err := absPath(args)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			problems, err := jetconfig.ValidateFile(p, cmdOpts.RootFlags().EnvName())
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			configFile := displayConfigPath(p)
+			if len(problems) == 0 {
+				jetlog.Logger(ctx).Printf("%s is valid\n", configFile)
+				return nil
+			}
+			for _, problem := range problems {
+				jetlog.Logger(ctx).Printf("%s:%s\n", configFile, problem)
+			}
+			return errorutil.NewUserErrorf(
+				"found %d problem(s) in %s",
+				len(problems),
+				configFile,
+			)
+		},
+	}
+
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Prints the JSON Schema for launchpad.yaml",
+		Long: "Prints the JSON Schema for launchpad.yaml. Save it to a file and add " +
+			"`# yaml-language-server: $schema=<path-to-file>` to the top of " +
+			"launchpad.yaml to get autocompletion and validation in your editor.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := jetconfig.JSONSchema()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+			return errors.WithStack(err)
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show [path]",
+		Short: "Prints a project's effective launchpad.yaml",
+		Long: "Prints a project's launchpad.yaml merged with the overlay for the " +
+			"selected environment (e.g. launchpad.prod.yaml for --environment prod), " +
+			"which is the config that other commands use.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jetCfg, err := RequireConfigFromFileSystem(cmd.Context(), cmd, args, cmdOpts)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			marshalledYaml, err := jetCfg.Marshal()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if jetCfg.OverlayPath() != "" {
+				fmt.Fprintf(
+					cmd.OutOrStdout(),
+					"# %s merged with %s\n",
+					filepath.Base(jetCfg.Path),
+					filepath.Base(jetCfg.OverlayPath()),
+				)
+			}
+			_, err = cmd.OutOrStdout().Write(marshalledYaml)
+			return errors.WithStack(err)
+		},
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <field> [path]",
+		Short: "Prints a field of a project's launchpad.yaml",
+		Long: "Prints a field of a project's launchpad.yaml, merged with the overlay " +
+			"for the selected environment. Fields are dot separated paths such as " +
+			"services.api.port. Lists and mappings are printed as yaml.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jetCfg, err := RequireConfigFromFileSystem(cmd.Context(), cmd, args[1:], cmdOpts)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			value, err := jetCfg.GetField(args[0])
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), value)
+			return errors.WithStack(err)
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <field> <value> [path]",
+		Short: "Sets a field of a project's launchpad.yaml",
+		Long: "Sets a field of a project's launchpad.yaml, keeping its comments and " +
+			"formatting. Fields are dot separated paths such as services.api.port. " +
+			"The value is parsed as yaml, so 8080 is a number and \"[a, b]\" is a list. " +
+			"The config is validated before it's saved.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p, err := absPath(args[2:])
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			jetCfg, err := jetconfig.RequireBaseFromFileSystem(
+				ctx,
+				p,
+				cmdOpts.RootFlags().EnvName(),
+			)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if err := jetCfg.SetField(args[0], args[1]); err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err := jetCfg.SaveConfig(jetCfg.Path); err != nil {
+				return errors.WithStack(err)
+			}
+			jetlog.Logger(ctx).Printf("Set %s in %s\n", args[0], displayConfigPath(p))
+			return nil
+		},
+	}
+
+	configCmd.AddCommand(upgradeCmd, validateCmd, schemaCmd, showCmd, getCmd, setCmd)
 
 	return configCmd
 }
 
+// displayConfigPath returns the path of the config at p relative to the working
+// directory, so that editors and terminals can link to it.
+func displayConfigPath(p string) string {
+	configFile := filepath.Join(jetconfig.ConfigDir(p), jetconfig.ConfigName(p))
+	wd, err := os.Getwd()
+	if err != nil {
+		return configFile
+	}
+	if rel, err := filepath.Rel(wd, configFile); err == nil {
+		return rel
+	}
+	return configFile
+}
+
 func RequireConfigFromFileSystem(
 	ctx context.Context,
 	cmd *cobra.Command,
@@ -57,7 +251,7 @@ func RequireConfigFromFileSystem(
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	c, err := jetconfig.RequireFromFileSystem(ctx, p, cmdOpts.RootFlags().Env())
+	c, err := jetconfig.RequireFromFileSystem(ctx, p, cmdOpts.RootFlags().EnvName())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -106,13 +300,8 @@ func loadOrInitConfigFromFileSystem(
 }
 
 func addFlagsToCmd(cmd *cobra.Command, cmdArgs []string, cfg *jetconfig.Config) error {
-	cfgEnvironment, ok := cfg.Environment[strings.ToLower(cmdOpts.RootFlags().Env().String())]
-	if !ok {
-		// If we didn't find any environment, then we can silently exit.
-		// The environment field is optional.
-		return nil
-	}
-	cfgFlags := cfgEnvironment.Flags
+	// The environment field is optional, in which case there are no flags.
+	cfgFlags := cfg.SelectedEnvironmentFields().Flags
 	p, err := absPath(cmdArgs)
 	if err != nil {
 		return errors.WithStack(err)
@@ -2,8 +2,12 @@ package command
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -12,11 +16,14 @@ import (
 	"github.com/spf13/cobra"
 	"go.jetpack.io/envsec"
 	"go.jetpack.io/launchpad/goutil"
+	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/launchpad"
 	"go.jetpack.io/launchpad/padcli/helm"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
 	"go.jetpack.io/launchpad/padcli/provider"
 	"go.jetpack.io/launchpad/pkg/jetlog"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 type HelmOptions struct {
@@ -51,17 +58,18 @@ func makeDeployOptions(
 	store envsec.Store,
 ) (*launchpad.DeployOptions, error) {
 
-	ns, err := cmdOpts.NamespaceProvider().Get(ctx, opts.Namespace, cluster.GetKubeContext(), cmdOpts.RootFlags().Env())
+	ns, err := getNamespace(ctx, jetCfg, opts.Namespace, cluster)
 	if err != nil {
 		return nil, err
 	}
 
 	hvc := helm.NewValueComputer(
-		cmdOpts.RootFlags().Env(),
+		cmdOpts.RootFlags().EnvName(),
 		ns,
 		opts.execQualifiedSymbol,
 		helm.NewImageProvider(
 			buildOutput.Image.String(),
+			buildOutput.ServiceLocalImages(),
 			publishOutput.PublishedImages(),
 			publishOutput.RegistryHost(),
 		),
@@ -79,7 +87,7 @@ func makeDeployOptions(
 	fmt.Fprintln(l, "\tProject:     "+boldSprint(jetCfg.GetProjectName()))
 	fmt.Fprintln(l, "\tNamespace:   "+boldSprint(ns))
 	fmt.Fprintln(l, "\tCluster:     "+boldSprint(cluster.GetKubeContext()))
-	fmt.Fprintln(l, "\tEnvironment: "+boldSprint(cmdOpts.RootFlags().Env().ToLower()))
+	fmt.Fprintln(l, "\tEnvironment: "+boldSprint(jetCfg.SelectedEnvironment()))
 
 	if err := hvc.Compute(ctx); err != nil {
 		return nil, errors.Wrap(err, "failed to compute helm values")
@@ -136,9 +144,50 @@ func makeDeployOptions(
 		return nil, errors.Errorf("Incompatible command line opts '%v' and '%v' : Prefer specifying a list of secret files using '%v'", mountSecretFileFlag, mountSecretFilesFlag, mountSecretFilesFlag)
 	}
 
-	appValues["secrets"] = appSecrets
-	appValues, err = helm.MergeValues(
-		appValues,
+	remoteEnvVars, err := getRemoteEnvVars(ctx, jetCfg, store)
+	if err != nil {
+		return nil, err
+	}
+	internalEnvVars, err := internalServiceEnvVars(jetCfg, ns, remoteEnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secrets that env vars in launchpad.yaml refer to, config files and
+	// volumes only go to the release of the services that use them.
+	// --env-override values take precedence.
+	releaseConfig := func(
+		release *launchpad.HelmOptions,
+		svcs []builderService,
+	) error {
+		secrets, err := envSecrets(svcs, remoteEnvVars)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		release.Values["secrets"] = lo.Assign(
+			secrets,
+			otherInternalServiceSecrets(internalEnvVars, svcs),
+			appSecrets,
+		)
+		release.PersistentVolumeClaims = volumeClaims(svcs)
+		release.ConfigMaps, err = configFilesConfigMaps(svcs)
+		return errors.WithStack(err)
+	}
+
+	app := &launchpad.HelmOptions{
+		Autoscaling:   mainAppAutoscaling(jetCfg),
+		ChartLocation: opts.App.ChartLocation,
+		Domains:       mainAppDomains(jetCfg, cluster),
+		InstanceName:  getInstanceName(jetCfg),
+		ReleaseName:   getReleaseName(jetCfg),
+		Values:        appValues,
+		Timeout:       lo.Ternary(len(jetCfg.Jobs()) > 0, 5*time.Minute, 0),
+	}
+	if err := releaseConfig(app, mainAppServices(jetCfg)); err != nil {
+		return nil, err
+	}
+	app.Values, err = helm.MergeValues(
+		app.Values,
 		lo.Map(
 			opts.App.ValueFiles, func(f string, _ int) string {
 				return filepath.Join(modulePath, f)
@@ -149,24 +198,66 @@ func makeDeployOptions(
 		return nil, errors.Wrap(err, "failed to merge app values")
 	}
 
-	remoteEnvVars, err := getRemoteEnvVars(ctx, jetCfg, store)
-	if err != nil {
-		return nil, err
+	// --helm.app.set and --helm.app.values only apply to the main app release.
+	additionalApps := additionalAppHelmOptions(jetCfg)
+	for _, additional := range additionalApps {
+		additional.ChartLocation = opts.App.ChartLocation
+		additional.Values, err = cmdOpts.Hooks().PostAppChartValuesCompute(
+			ctx,
+			cmdOpts,
+			hvc.WithAppValues(hvc.AdditionalAppValues()[additional.Name]),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if w, ok := additional.service.(jetconfig.Web); ok {
+			additional.Domains = domains(jetCfg, w, cluster)
+		}
+		err := releaseConfig(&additional.HelmOptions, []builderService{additional.service})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	externalCharts := jetconfigHelmToChartConfig(jetCfg, ns)
+	for i, hc := range jetCfg.HelmCharts() {
+		if externalCharts[i].Values, err = helmChartValues(hc); err != nil {
+			return nil, err
+		}
+	}
+
+	var preDeploy *launchpad.HelmOptions
+	if values := hvc.PreDeployValues(); values != nil {
+		preDeploy = preDeployHelmOptions(jetCfg)
+		preDeploy.ChartLocation = opts.App.ChartLocation
+		preDeploy.Values, err = cmdOpts.Hooks().PostAppChartValuesCompute(
+			ctx,
+			cmdOpts,
+			hvc.WithAppValues(values),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := releaseConfig(preDeploy, preDeployServices(jetCfg)); err != nil {
+			return nil, err
+		}
+		// Migrations can take a while, and the deploy waits for them.
+		preDeploy.Timeout = preDeployTimeout(jetCfg)
 	}
 
 	return &launchpad.DeployOptions{
-		App: &launchpad.HelmOptions{
-			ChartLocation: opts.App.ChartLocation,
-			InstanceName:  getInstanceName(jetCfg),
-			ReleaseName:   getReleaseName(jetCfg),
-			Values:        appValues,
-			Timeout:       lo.Ternary(len(jetCfg.Jobs()) > 0, 5*time.Minute, 0),
-		},
+		App: app,
+		AdditionalApps: lo.Map(
+			additionalApps,
+			func(app *additionalApp, _ int) *launchpad.HelmOptions { return &app.HelmOptions },
+		),
+		PreDeploy:                   preDeploy,
 		CreateNamespace:             hvc.CreateNamespace(),
-		Environment:                 cmdOpts.RootFlags().Env().String(),
-		ExternalCharts:              jetconfigHelmToChartConfig(jetCfg, ns),
+		Environment:                 strings.ToUpper(jetCfg.SelectedEnvironment()),
+		ExternalCharts:              externalCharts,
 		JetCfg:                      jetCfg,
 		IsLocalCluster:              cluster.IsLocal(),
+		JobRetention:                jetCfg.JobRetention(),
 		KubeContext:                 cluster.GetKubeContext(),
 		LifecycleHook:               cmdOpts.Hooks().Deploy,
 		Namespace:                   ns,
@@ -184,3 +275,214 @@ func getReleaseName(jetCfg *jetconfig.Config) string {
 func getInstanceName(jetCfg *jetconfig.Config) string {
 	return helm.ToValidName(jetCfg.GetInstanceName())
 }
+
+// additionalApp is an app chart release for a web service other than the
+// first one, which is deployed as part of the main release, or for an internal
+// service or worker.
+type additionalApp struct {
+	launchpad.HelmOptions
+	Name    string // service name
+	service builderService
+}
+
+// internalServiceEnvVars returns, for each internal service, the env vars that
+// tell the project's other services where to reach it. For an internal service
+// named "user-api" these are USER_API_HOST, USER_API_PORT and USER_API_URL. It
+// fails if the user set an env var with one of these names, or another service
+// has one in its env, rather than silently overriding it.
+func internalServiceEnvVars(
+	jetCfg *jetconfig.Config,
+	namespace string,
+	remoteEnvVars map[string]string,
+) (map[string]map[string]string, error) {
+	builders := jetCfg.Builders()
+	builderNames := maps.Keys(builders)
+	slices.Sort(builderNames)
+	result := map[string]map[string]string{}
+	for _, svc := range jetCfg.InternalServices() {
+		host := launchpad.AppServiceHostname(helm.ToValidName(svc.GetUniqueName()), namespace)
+		prefix := envVarName(svc.GetName())
+		envVars := map[string]string{
+			prefix + "_HOST": host,
+			prefix + "_PORT": strconv.Itoa(svc.GetPort()),
+			prefix + "_URL":  fmt.Sprintf("http://%s:%d", host, svc.GetPort()),
+		}
+		names := maps.Keys(envVars)
+		slices.Sort(names)
+		for _, name := range names {
+			if _, ok := remoteEnvVars[name]; ok {
+				return nil, errorutil.NewUserErrorf(
+					"env var %s is set, but launchpad sets it to the address of "+
+						"internal service %s. Rename the env var",
+					name,
+					svc.GetName(),
+				)
+			}
+			for _, b := range builderNames {
+				if _, ok := builders[b].GetEnv()[name]; ok && b != svc.GetName() {
+					return nil, errorutil.NewUserErrorf(
+						"env %s of service %s is set, but launchpad sets it to the "+
+							"address of internal service %s. Rename the env var",
+						name,
+						b,
+						svc.GetName(),
+					)
+				}
+			}
+		}
+		result[svc.GetName()] = envVars
+	}
+	return result, nil
+}
+
+// otherInternalServiceSecrets returns the env vars of the internal services
+// that aren't in svcs, base64 encoded as app chart secrets. An internal service
+// doesn't need its own address.
+func otherInternalServiceSecrets(
+	internalEnvVars map[string]map[string]string,
+	svcs []builderService,
+) map[string]string {
+	secrets := map[string]string{}
+	for name, envVars := range internalEnvVars {
+		if lo.ContainsBy(svcs, func(svc builderService) bool { return svc.GetName() == name }) {
+			continue
+		}
+		for k, v := range envVars {
+			secrets[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+	return secrets
+}
+
+var nonEnvVarChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+func envVarName(name string) string {
+	return nonEnvVarChars.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+func additionalAppHelmOptions(jetCfg *jetconfig.Config) []*additionalApp {
+	svcs := append(
+		lo.Map(
+			lo.Drop(jetCfg.WebServices(), 1),
+			func(w jetconfig.Web, _ int) builderService { return w },
+		),
+		lo.Map(
+			jetCfg.InternalServices(),
+			func(i jetconfig.Internal, _ int) builderService { return i },
+		)...,
+	)
+	svcs = append(
+		svcs,
+		lo.Map(
+			jetCfg.Workers(),
+			func(w jetconfig.Worker, _ int) builderService { return w },
+		)...,
+	)
+	return lo.Map(
+		svcs,
+		func(svc builderService, _ int) *additionalApp {
+			app := &additionalApp{
+				HelmOptions: launchpad.HelmOptions{
+					InstanceName: helm.ToValidName(svc.GetUniqueName()),
+					ReleaseName:  getReleaseName(jetCfg) + "-" + helm.ToValidName(svc.GetName()),
+				},
+				Name:    svc.GetName(),
+				service: svc,
+			}
+			if w, ok := svc.(jetconfig.Web); ok {
+				app.Autoscaling = autoscaling(w)
+			}
+			return app
+		},
+	)
+}
+
+// preDeployHelmOptions returns the names of the app chart release that runs
+// the project's pre-deploy jobs.
+func preDeployHelmOptions(jetCfg *jetconfig.Config) *launchpad.HelmOptions {
+	return &launchpad.HelmOptions{
+		InstanceName: helm.ToValidName(
+			jetCfg.GetProjectName() + "-" + jetconfig.PreDeployReleaseSuffix,
+		),
+		ReleaseName: getReleaseName(jetCfg) + "-" + jetconfig.PreDeployReleaseSuffix,
+	}
+}
+
+// preDeployTimeout is how long the deploy waits for pre-deploy jobs: 5
+// minutes, or longer if a job's activeDeadlineSeconds allows it to run longer.
+func preDeployTimeout(jetCfg *jetconfig.Config) time.Duration {
+	timeout := 5 * time.Minute
+	for _, j := range jetCfg.Jobs() {
+		if deadline := j.GetActiveDeadlineSeconds(); j.IsPreDeploy() && deadline != nil {
+			timeout = lo.Max([]time.Duration{timeout, time.Duration(*deadline) * time.Second})
+		}
+	}
+	return timeout
+}
+
+// mainAppAutoscaling returns the autoscaling of the first web service, which
+// is deployed as part of the main app release.
+func mainAppAutoscaling(jetCfg *jetconfig.Config) *launchpad.Autoscaling {
+	if websvcs := jetCfg.WebServices(); len(websvcs) > 0 {
+		return autoscaling(websvcs[0])
+	}
+	return nil
+}
+
+// autoscaling returns the HPA settings of a web service, or nil if it has a
+// fixed number of replicas.
+func autoscaling(w jetconfig.Web) *launchpad.Autoscaling {
+	replicas := w.GetReplicas()
+	if !replicas.IsAutoscaled() {
+		return nil
+	}
+	return &launchpad.Autoscaling{
+		MinReplicas:  replicas.Min,
+		MaxReplicas:  replicas.Max,
+		TargetCPU:    replicas.TargetCPU,
+		TargetMemory: replicas.TargetMemory,
+		Labels:       w.GetLabels(),
+		Annotations:  w.GetAnnotations(),
+	}
+}
+
+// mainAppDomains returns the custom domains of the first web service, which
+// is deployed as part of the main app release.
+func mainAppDomains(jetCfg *jetconfig.Config, cluster provider.Cluster) *launchpad.Domains {
+	if websvcs := jetCfg.WebServices(); len(websvcs) > 0 {
+		return domains(jetCfg, websvcs[0], cluster)
+	}
+	return nil
+}
+
+// domains returns the custom domains of a web service, or nil if it has none.
+// Local clusters ignore domains, because Let's Encrypt can't reach them to
+// issue certificates.
+func domains(
+	jetCfg *jetconfig.Config,
+	w jetconfig.Web,
+	cluster provider.Cluster,
+) *launchpad.Domains {
+	hostnames := w.GetDomains()
+	if len(hostnames) == 0 || cluster.IsLocal() {
+		return nil
+	}
+	controller := jetCfg.Ingress.Controller
+	if controller == "" {
+		controller = lo.Ternary(
+			cluster.IsJetpackManaged(),
+			jetconfig.IngressControllerAmbassador,
+			jetconfig.IngressControllerIngress,
+		)
+	}
+	return &launchpad.Domains{
+		Hostnames:        hostnames,
+		Ambassador:       controller == jetconfig.IngressControllerAmbassador,
+		IngressClassName: jetCfg.Ingress.ClassName,
+		ACMEEmail:        jetCfg.Ingress.ACMEEmail,
+		ClusterIssuer:    jetCfg.Ingress.ClusterIssuer,
+		ServicePort:      w.GetPort(),
+		Labels:           w.GetLabels(),
+		Annotations:      w.GetAnnotations(),
+	}
+}
@@ -211,7 +211,7 @@ func autoDeploy(
 				return errors.Wrap(bpdErr, "failed to tail pod logs")
 			}
 		} else {
-			err := printUpSuccess(ctx, deployOutput, cluster)
+			err := printUpSuccess(ctx, deployOutput, jetCfg, cluster)
 			if err != nil {
 				logsAndPortFwdCancelFn()
 				return errors.Wrap(err, "failed to print namespace and app URL")
@@ -258,7 +258,9 @@ func tailLogsAndPortForward(
 			return errors.WithStack(err)
 		}
 
-		if hasDepl {
+		// Only the first web service runs in the main app release and listens
+		// on its port. Internal services and workers have their own releases.
+		if hasDepl && len(jetCfg.WebServices()) > 0 {
 			pfopts := &launchpad.PortForwardOptions{
 				DeployOut: deployOut,
 				KubeCtx:   kubeCtx,
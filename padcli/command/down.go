@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/launchpad"
 	"go.jetpack.io/launchpad/padcli/command/jflags"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
+	"go.jetpack.io/launchpad/padcli/terminal"
 	"go.jetpack.io/launchpad/pkg/jetlog"
 )
 
@@ -77,16 +80,54 @@ func makeLaunchpadDownOptions(
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	ns, err := cmdOpts.NamespaceProvider().Get(ctx, flags.Namespace(), cluster.GetKubeContext(), cmdOpts.RootFlags().Env())
+	ns, err := getNamespace(ctx, jetCfg, flags.Namespace(), cluster)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	deleteVolumes, err := shouldDeleteVolumes(jetCfg, flags)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	return &launchpad.DownOptions{
+		// The pre-deploy release is included even if the project no longer has
+		// pre-deploy jobs, so that it's removed if it was installed before.
+		AdditionalApps: append(
+			lo.Map(
+				additionalAppHelmOptions(jetCfg),
+				func(app *additionalApp, _ int) *launchpad.HelmOptions { return &app.HelmOptions },
+			),
+			preDeployHelmOptions(jetCfg),
+		),
 		ExternalCharts: jetconfigHelmToChartConfig(jetCfg, ns),
 		ReleaseName:    getReleaseName(jetCfg),
 		InstanceName:   getInstanceName(jetCfg),
 		Namespace:      ns,
 		KubeContext:    cluster.GetKubeContext(),
+		DeleteVolumes:  deleteVolumes,
 	}, nil
 }
+
+// shouldDeleteVolumes returns true if the user chose to delete the project's
+// volumes, either with --delete-volumes or when asked. Volumes are kept in
+// non-interactive terminals, so that no data is lost by accident.
+func shouldDeleteVolumes(jetCfg *jetconfig.Config, flags *jflags.DownCmd) (bool, error) {
+	if flags.DeleteVolumes() || flags.KeepVolumes() {
+		return flags.DeleteVolumes(), nil
+	}
+	hasVolumes := lo.SomeBy(jetCfg.Services, func(svc jetconfig.Service) bool {
+		s, ok := svc.(interface{ GetVolumes() []jetconfig.Volume })
+		return ok && len(s.GetVolumes()) > 0
+	})
+	if !hasVolumes || !terminal.IsInteractive() {
+		return false, nil
+	}
+
+	deleteVolumes := false
+	err := survey.AskOne(&survey.Confirm{
+		Message: "Delete the project's volumes too? All of their data will be lost",
+		Default: false,
+	}, &deleteVolumes)
+	return deleteVolumes, errors.WithStack(err)
+}
@@ -5,9 +5,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"go.jetpack.io/envsec"
 	"go.jetpack.io/envsec/pkg/envcli"
@@ -56,7 +58,7 @@ func envCmd() *cobra.Command {
 			envId, err := cmdOpts.EnvSecProvider().NewEnvId(
 				ctx,
 				jetCfg.GetProjectID(),
-				cmdOpts.RootFlags().Env().String(),
+				strings.ToUpper(jetCfg.SelectedEnvironment()),
 			)
 			if err != nil {
 				return errors.WithStack(err)
@@ -71,9 +73,11 @@ func envCmd() *cobra.Command {
 			}
 
 			envcli.BootstrapConfig(&envcli.CmdConfig{
-				Store:    store,
-				EnvID:    *envId,
-				EnvNames: []string{"DEV", "PROD", "STAGING"},
+				Store: store,
+				EnvID: *envId,
+				EnvNames: lo.Map(jetCfg.Environments(), func(env string, _ int) string {
+					return strings.ToUpper(env)
+				}),
 			})
 			return nil
 		},
@@ -192,7 +196,8 @@ func envStore(
 		return nil, errors.WithStack(err)
 	}
 
-	jetCfg, err := jetconfig.RequireFromFileSystem(ctx, curDir, cmdOpts.RootFlags().Env())
+	// The config may be saved below, so don't merge environment overlays into it.
+	jetCfg, err := jetconfig.RequireBaseFromFileSystem(ctx, curDir, cmdOpts.RootFlags().EnvName())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
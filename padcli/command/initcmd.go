@@ -72,7 +72,7 @@ func initConfig(ctx context.Context, authProvider provider.Auth, path string) er
 		appName = "my-app"
 	}
 	// check if jetconfig file exists
-	_, err = jetconfig.RequireFromFileSystem(ctx, path, cmdOpts.RootFlags().Env())
+	_, err = jetconfig.RequireFromFileSystem(ctx, path, cmdOpts.RootFlags().EnvName())
 	if err == nil {
 		jetlog.Logger(ctx).Printf(
 			"%s already exists. Please edit directly",
@@ -111,6 +111,13 @@ func initConfig(ctx context.Context, authProvider provider.Auth, path string) er
 			[]string{"/bin/sh", "-c", "date; echo Hello from Launchpad"},
 			"* * * * *",
 		)
+	} else if answers.AppType == string(provider.WorkerServiceType) {
+		jetCfg.AddNewWorkerService(
+			answers.AppName+"-"+jetconfig.WorkerType,
+			[]string{"/bin/sh", "-c", "while true; do date; echo Hello from Launchpad; sleep 60; done"},
+		)
+	} else if answers.AppType == string(provider.InternalServiceType) {
+		jetCfg.AddNewInternalService(answers.AppName + "-" + jetconfig.InternalType)
 	}
 
 	if answers.ClusterOption != "" && answers.ClusterOption != provider.CreateJetpackCluster {
@@ -23,12 +23,27 @@ func RegisterDownFlags(cmd *cobra.Command, flags *DownCmd, p provider.Providers)
 		"",
 		"App install name",
 	)
+	cmd.Flags().BoolVar(
+		&flags.keepVolumes,
+		"keep-volumes",
+		false,
+		"Keep the app's volumes and their data",
+	)
+	cmd.Flags().BoolVar(
+		&flags.deleteVolumes,
+		"delete-volumes",
+		false,
+		"Delete the app's volumes and their data",
+	)
+	cmd.MarkFlagsMutuallyExclusive("keep-volumes", "delete-volumes")
 	RegisterCommonFlags(cmd, p)
 }
 
 type DownCmd struct {
-	app       string
-	namespace string
+	app           string
+	namespace     string
+	keepVolumes   bool
+	deleteVolumes bool
 }
 
 func (f *DownCmd) App() string {
@@ -38,3 +53,11 @@ func (f *DownCmd) App() string {
 func (f *DownCmd) Namespace() string {
 	return f.namespace
 }
+
+func (f *DownCmd) KeepVolumes() bool {
+	return f.keepVolumes
+}
+
+func (f *DownCmd) DeleteVolumes() bool {
+	return f.deleteVolumes
+}
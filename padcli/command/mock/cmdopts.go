@@ -7,7 +7,6 @@ import (
 	"go.jetpack.io/launchpad/padcli/flags"
 	"go.jetpack.io/launchpad/padcli/hook"
 	"go.jetpack.io/launchpad/padcli/provider"
-	"go.jetpack.io/launchpad/proto/api"
 )
 
 // We should try to make all these mocks unnecessary. For example the Namespace
@@ -137,7 +136,7 @@ func (*mockNamespaceProvider) Get(
 	ctx context.Context,
 	ns string,
 	kubeContextName string,
-	e api.Environment,
+	env string,
 ) (string, error) {
 	if ns != "" {
 		return ns, nil
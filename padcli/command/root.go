@@ -61,12 +61,13 @@ func registerRootCmdFlags(cmd *cobra.Command) {
 	)
 	_ = cmd.PersistentFlags().MarkHidden("skip-version-check")
 
-	// to read this flag, one must use the cmdOpts.RootFlags().Env() function
+	// to read this flag, one must use the cmdOpts.RootFlags().EnvName() function
 	cmd.PersistentFlags().StringVar(
 		&cmdOpts.RootFlags().Environment,
 		environmentFlagName,
 		"dev",
-		"the name of the environment this command should operate on. One of: dev, prod",
+		"the name of the environment this command should operate on. One of: dev, staging, prod, "+
+			"or an environment declared in launchpad.yaml",
 	)
 }
 
@@ -211,7 +212,8 @@ func persistentPreRunE(cmd *cobra.Command, args []string) error {
 
 	if !cmdOpts.RootFlags().IsValidEnvironment() {
 		return errorutil.NewUserErrorf(
-			"Environment \"%s\" not recognized. Please use one of: dev, prod.\n",
+			"Environment \"%s\" is not a valid environment name. Please use one of: "+
+				"dev, staging, prod, or an environment declared in launchpad.yaml.\n",
 			cmdOpts.RootFlags().Environment,
 		)
 	}
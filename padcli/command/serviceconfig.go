@@ -0,0 +1,164 @@
+package command
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/padcli/helm"
+	"go.jetpack.io/launchpad/padcli/jetconfig"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// builderService is a service that runs containers, i.e. any service other
+// than a helm chart.
+type builderService interface {
+	jetconfig.Builder
+	jetconfig.Service
+}
+
+// maxConfigFilesSize is the maximum size of a ConfigMap.
+const maxConfigFilesSize = 1024 * 1024
+
+// mainAppServices returns the services deployed by the main app release: the
+// first web service, cronjobs and jobs that are not pre-deploy jobs.
+func mainAppServices(jetCfg *jetconfig.Config) []builderService {
+	svcs := []builderService{}
+	if websvcs := jetCfg.WebServices(); len(websvcs) > 0 {
+		svcs = append(svcs, websvcs[0])
+	}
+	for _, c := range jetCfg.Cronjobs() {
+		svcs = append(svcs, c)
+	}
+	for _, j := range jetCfg.Jobs() {
+		if !j.IsPreDeploy() {
+			svcs = append(svcs, j)
+		}
+	}
+	return svcs
+}
+
+// preDeployServices returns the jobs deployed by the pre-deploy release.
+func preDeployServices(jetCfg *jetconfig.Config) []builderService {
+	return lo.FilterMap(jetCfg.Jobs(), func(j jetconfig.Job, _ int) (builderService, bool) {
+		return j, j.IsPreDeploy()
+	})
+}
+
+// envSecrets returns the env vars of svcs that refer to secrets, with their
+// values from remoteEnvVars base64 encoded as app chart secrets. The secrets
+// of a release are shared by its services, so services in the same release
+// can't use one env var name for different secrets.
+func envSecrets(
+	svcs []builderService,
+	remoteEnvVars map[string]string,
+) (map[string]string, error) {
+	secrets := map[string]string{}
+	owners := map[string]string{} // env var name to the service that set it
+	for _, svc := range svcs {
+		env := svc.GetEnv()
+		names := maps.Keys(env)
+		slices.Sort(names)
+		for _, name := range names {
+			key := env[name].Secret
+			if key == "" {
+				continue
+			}
+			value, ok := remoteEnvVars[key]
+			if !ok {
+				return nil, errorutil.NewUserErrorf(
+					"env %s of service %s refers to secret %s, which is not set. "+
+						"Set it with `launchpad env set %s=<value>`",
+					name,
+					svc.GetName(),
+					key,
+					key,
+				)
+			}
+			encoded := base64.StdEncoding.EncodeToString([]byte(value))
+			if owner, ok := owners[name]; ok && secrets[name] != encoded {
+				return nil, errorutil.NewUserErrorf(
+					"env %s of services %s and %s refers to different secrets, but "+
+						"they are deployed together and share their env vars. Use "+
+						"different names, or the same secret",
+					name,
+					owner,
+					svc.GetName(),
+				)
+			}
+			secrets[name] = encoded
+			owners[name] = svc.GetName()
+		}
+	}
+	return secrets, nil
+}
+
+// configFilesConfigMaps reads the config files of svcs and returns a ConfigMap
+// for each service that has any. Namespace and launchpad's labels are set when
+// they are applied.
+func configFilesConfigMaps(svcs []builderService) ([]*komponents.ConfigMap, error) {
+	configMaps := []*komponents.ConfigMap{}
+	for _, svc := range svcs {
+		files := svc.GetConfigFiles()
+		if len(files) == 0 {
+			continue
+		}
+		cm := &komponents.ConfigMap{
+			Name:        helm.ConfigFilesName(svc),
+			Labels:      svc.GetLabels(),
+			Annotations: svc.GetAnnotations(),
+			Data:        map[string]string{},
+		}
+		size := 0
+		for _, f := range files {
+			contents, err := os.ReadFile(filepath.Join(svc.GetPath(), f.Path))
+			if os.IsNotExist(err) {
+				return nil, errorutil.NewUserErrorf(
+					"config file %s of service %s does not exist",
+					f.Path,
+					svc.GetName(),
+				)
+			} else if err != nil {
+				return nil, errors.Wrapf(err, "failed to read config file %s", f.Path)
+			}
+			size += len(contents)
+			cm.Data[f.Key()] = string(contents)
+		}
+		if size > maxConfigFilesSize {
+			return nil, errorutil.NewUserErrorf(
+				"config files of service %s are larger than 1MiB in total",
+				svc.GetName(),
+			)
+		}
+		configMaps = append(configMaps, cm)
+	}
+	return configMaps, nil
+}
+
+// volumeClaims returns a PersistentVolumeClaim for each volume of svcs.
+// Namespace and launchpad's labels are set when they are applied.
+func volumeClaims(svcs []builderService) []*komponents.PersistentVolumeClaim {
+	claims := []*komponents.PersistentVolumeClaim{}
+	for _, svc := range svcs {
+		s, ok := svc.(interface{ GetVolumes() []jetconfig.Volume })
+		if !ok {
+			continue
+		}
+		for _, v := range s.GetVolumes() {
+			claims = append(claims, &komponents.PersistentVolumeClaim{
+				Name:         helm.VolumeClaimName(svc, v),
+				Labels:       svc.GetLabels(),
+				Annotations:  svc.GetAnnotations(),
+				Size:         v.Size,
+				AccessMode:   string(v.GetAccessMode()),
+				StorageClass: v.StorageClass,
+			})
+		}
+	}
+	return claims
+}
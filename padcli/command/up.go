@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -127,7 +128,7 @@ func upCmd() *cobra.Command {
 				)
 			}
 
-			return printUpSuccess(ctx, do, cluster)
+			return printUpSuccess(ctx, do, jetCfg, cluster)
 		},
 
 		PostRun: func(cmd *cobra.Command, args []string) {
@@ -227,8 +228,8 @@ func registerDeployFlags(cmd *cobra.Command, opts *deployOptions) {
 	_ = cmd.Flags().MarkHidden(minReplicaFlag)
 	_ = cmd.Flags().MarkDeprecated(
 		minReplicaFlag,
-		"This flag is deprecated and no longer does anything. min replicas is "+
-			"always 1",
+		"This flag is deprecated and no longer does anything. Set replicas "+
+			"of your web services in launchpad.yaml instead",
 	)
 
 	cmd.Flags().StringSliceVar(
@@ -325,6 +326,7 @@ func buildPublishAndDeploy(
 func printUpSuccess(
 	ctx context.Context,
 	do *launchpad.DeployOutput,
+	jetCfg *jetconfig.Config,
 	c provider.Cluster,
 ) error {
 	jetlog.Logger(ctx).Println(green.Sprintf(
@@ -332,37 +334,134 @@ func printUpSuccess(
 		do.Namespace,
 	))
 
-	// TODO(Landau) This gets more complicated when we add internal services.
-	// Consider adding ambassador.enabled value.
-	values := do.Releases[launchpad.AppChartName].Config
-	if fmt.Sprintf("%v", values["replicaCount"]) == "0" {
-		return nil
-	}
+	appReleases := do.AppReleases()
+	for _, r := range appReleases {
+		values := r.Config
+		if fmt.Sprintf("%v", values["replicaCount"]) == "0" {
+			continue
+		}
+
+		instanceName := values["jetpack"].(map[string]any)["instanceName"].(string)
+		appLabel := "App"
+		if len(appReleases) > 1 {
+			appLabel = fmt.Sprintf("App %s", instanceName)
+		}
+
+		if status := do.Replicas[instanceName]; status != nil {
+			jetlog.Logger(ctx).Println(green.Sprintf(
+				"%s has %s",
+				appLabel,
+				replicaStatusString(status),
+			))
+		}
+
+		if svc, ok := values["service"].(map[string]any); ok && svc["enabled"] == false {
+			// Workers are not reachable
+			continue
+		}
 
-	if c.IsLocal() {
-		name := values["jetpack"].(map[string]any)["instanceName"].(string) +
+		if amby, ok := values["ambassador"].(map[string]any); ok && amby["enabled"] == false {
+			// Internal services are only reachable from inside the cluster
+			jetlog.Logger(ctx).Println(green.Sprintf(
+				"%s reachable inside the cluster at %s:%v",
+				appLabel,
+				launchpad.AppServiceHostname(instanceName, do.Namespace),
+				values["podPort"],
+			))
+			continue
+		}
+
+		if c.IsLocal() {
 			// Ugh, this makes me so sad
-			"-" + launchpad.AppChartName
-		port, err := k8s.ServiceNodePort(ctx, name, do.Namespace, c.GetKubeContext())
-		if err != nil {
-			return errors.Wrap(err, "failed to get service node port")
+			name := launchpad.AppServiceName(instanceName)
+			port, err := k8s.ServiceNodePort(ctx, name, do.Namespace, c.GetKubeContext())
+			if err != nil {
+				return errors.Wrap(err, "failed to get service node port")
+			}
+			jetlog.Logger(ctx).Println(
+				green.Sprintf("%s reachable at http://localhost:%d", appLabel, port),
+			)
+			continue
 		}
-		jetlog.Logger(ctx).Println(
-			green.Sprintf("App reachable at http://localhost:%d", port),
-		)
-		return nil
-	}
 
-	if amby, ok := values["ambassador"].(map[string]any); ok {
-		host := amby["hostname"].(string)
-		if host != "" {
-			jetlog.Logger(ctx).Println(green.Sprintf("App reachable at https://%s", host))
+		if amby, ok := values["ambassador"].(map[string]any); ok {
+			host := amby["hostname"].(string)
+			if host != "" {
+				jetlog.Logger(ctx).Println(green.Sprintf("%s reachable at https://%s", appLabel, host))
+			}
 		}
 	}
 
+	printDNSRecords(ctx, do.DNSRecords)
+	printCronjobNextRuns(ctx, jetCfg, time.Now())
 	return nil
 }
 
+// printDNSRecords tells the user which DNS records to create, so that the
+// custom domains of web services reach the cluster.
+func printDNSRecords(ctx context.Context, records []launchpad.DNSRecord) {
+	if len(records) == 0 {
+		return
+	}
+	l := jetlog.Logger(ctx)
+	l.Println(green.Sprint("Create these DNS records, so that your domains reach the app:"))
+	for _, r := range records {
+		if r.Value == "" {
+			l.IndentedPrintln(
+				"%s: the load balancer of your ingress controller doesn't have an "+
+					"address yet. Run `kubectl get ingress,svc -A` to find it",
+				r.Name,
+			)
+			continue
+		}
+		l.IndentedPrintln("%s %s %s", r.Name, r.Type, r.Value)
+	}
+	l.Println(green.Sprint(
+		"The app is reachable at https://<domain> once its certificate is " +
+			"issued, a few minutes after the records resolve",
+	))
+}
+
+// numCronjobNextRuns is how many upcoming runs are shown for each cronjob
+const numCronjobNextRuns = 3
+
+// printCronjobNextRuns prints when each cronjob runs next, in its time zone.
+func printCronjobNextRuns(ctx context.Context, jetCfg *jetconfig.Config, now time.Time) {
+	for _, cj := range jetCfg.Cronjobs() {
+		if cj.IsSuspended() {
+			jetlog.Logger(ctx).Println(green.Sprintf("Cronjob %s is suspended", cj.GetName()))
+			continue
+		}
+		runs, err := jetconfig.NextRuns(cj, now, numCronjobNextRuns)
+		if err != nil || len(runs) == 0 {
+			// The schedule is validated when the config loads, so this is a
+			// schedule such as 0 0 30 2 * that never runs.
+			jetlog.Logger(ctx).Println(green.Sprintf("Cronjob %s never runs", cj.GetName()))
+			continue
+		}
+		jetlog.Logger(ctx).Println(green.Sprintf(
+			"Cronjob %s next runs at %s",
+			cj.GetName(),
+			strings.Join(lo.Map(runs, func(t time.Time, _ int) string {
+				return t.Format("2006-01-02 15:04 MST")
+			}), ", "),
+		))
+	}
+}
+
+// replicaStatusString returns e.g. "2/3 replicas ready (autoscaling 2-10)"
+func replicaStatusString(status *launchpad.ReplicaStatus) string {
+	result := fmt.Sprintf("%d/%d replicas ready", status.Ready, status.Desired)
+	if status.Autoscaling != nil {
+		result += fmt.Sprintf(
+			" (autoscaling %d-%d)",
+			status.Autoscaling.MinReplicas,
+			status.Autoscaling.MaxReplicas,
+		)
+	}
+	return result
+}
+
 func readEnvVariables(
 	projectPath string,
 	envFile string,
@@ -438,10 +537,7 @@ func makePublishOptions(
 		)
 	}
 
-	localImagesToPublish := []*launchpad.LocalImage{}
-	if buildOutput.DidBuildUsingDockerfile() {
-		localImagesToPublish = append(localImagesToPublish, buildOutput.Image)
-	}
+	localImagesToPublish := buildOutput.Images()
 
 	for _, service := range config.Builders() {
 		if service.ShouldPublish() {
@@ -456,7 +552,7 @@ func makePublishOptions(
 		ImageRegistryWithRepo: imageRegistryWithRepo,
 		LifecycleHook:         cmdOpts.Hooks().Publish,
 		LocalImages:           localImagesToPublish,
-		TagPrefix:             cmdOpts.RootFlags().Env().ImageTagPrefix(),
+		TagPrefix:             config.ImageTagPrefix(),
 	}
 
 	if repoConfig != nil {
@@ -2,19 +2,22 @@ package command
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"go.jetpack.io/launchpad/launchpad"
 	"go.jetpack.io/launchpad/padcli/command/mock"
 	"go.jetpack.io/launchpad/padcli/flags"
 	"go.jetpack.io/launchpad/padcli/helm"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
-	"go.jetpack.io/launchpad/proto/api"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
 )
 
 // Testing docker build publish and deploy for local registry
@@ -262,7 +265,7 @@ func (t *Suite) TestComputeHelmValues() {
 				clusterHostname,
 			)
 			hvc := helm.NewValueComputer(
-				api.Environment_DEV,
+				"dev",
 				tc.opts.Namespace,
 				tc.opts.execQualifiedSymbol,
 				nil, // published images
@@ -275,3 +278,322 @@ func (t *Suite) TestComputeHelmValues() {
 		})
 	}
 }
+
+func (t *Suite) TestInternalServiceEnvVars() {
+	req := t.Require()
+	jetCfg := &jetconfig.Config{
+		ProjectID: "proj_4pss8BskaTPOWzuhyY7cfL",
+		Name:      "py-dockerfile",
+		Services:  []jetconfig.Service{},
+	}
+	jetCfg.AddNewWebService("api")
+	jetCfg.AddNewInternalService("user-api")
+	jetCfg.AddNewInternalService("billing")
+
+	envVars, err := internalServiceEnvVars(jetCfg, "my-ns", map[string]string{"DB": "x"})
+	req.NoError(err)
+	req.Equal(
+		map[string]string{
+			"USER_API_HOST": "py-dockerfile-user-api-app.my-ns.svc.cluster.local",
+			"USER_API_PORT": "8080",
+			"USER_API_URL":  "http://py-dockerfile-user-api-app.my-ns.svc.cluster.local:8080",
+		},
+		envVars["user-api"],
+	)
+
+	apps := additionalAppHelmOptions(jetCfg)
+	req.Len(apps, 2)
+	req.Equal("py-dockerfile-user-api", apps[0].InstanceName)
+	req.Equal("proj-4pss8bskatpowzuhyy7cfl-user-api", apps[0].ReleaseName)
+
+	// An internal service gets the addresses of the others, but not its own
+	secrets := otherInternalServiceSecrets(envVars, []builderService{apps[0].service})
+	req.Equal(
+		base64.StdEncoding.EncodeToString([]byte("py-dockerfile-billing-app.my-ns.svc.cluster.local")),
+		secrets["BILLING_HOST"],
+	)
+	req.NotContains(secrets, "USER_API_HOST")
+	req.Len(secrets, 3)
+	req.Len(otherInternalServiceSecrets(envVars, mainAppServices(jetCfg)), 6)
+
+	_, err = internalServiceEnvVars(
+		jetCfg,
+		"my-ns",
+		map[string]string{"USER_API_URL": "http://localhost:8080"},
+	)
+	req.ErrorContains(
+		err,
+		"env var USER_API_URL is set, but launchpad sets it to the address of internal service user-api",
+	)
+}
+
+// loadConfig loads the dev environment of a launchpad.yaml with the project
+// fields and yamlFields. files maps the names of other files in the project
+// directory to their contents.
+func (t *Suite) loadConfig(yamlFields string, files map[string]string) *jetconfig.Config {
+	req := t.Require()
+	dir := t.T().TempDir()
+	files = lo.Assign(files, map[string]string{
+		"launchpad.yaml": `configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+` + yamlFields,
+	})
+	for name, contents := range files {
+		req.NoError(os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	jetCfg, err := jetconfig.RequireFromFileSystem(context.Background(), dir, "dev")
+	req.NoError(err)
+	return jetCfg
+}
+
+func (t *Suite) TestPreDeployRelease() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`services:
+  api:
+    type: web
+  migrate:
+    type: job
+    command: [python, manage.py, migrate]
+    runBefore: [api]
+    activeDeadlineSeconds: 900
+`, nil)
+	req.Equal(15*time.Minute, preDeployTimeout(jetCfg))
+
+	opts := preDeployHelmOptions(jetCfg)
+	req.Equal("py-dockerfile-pre-deploy", opts.InstanceName)
+	req.Equal("proj-4pss8bskatpowzuhyy7cfl-pre-deploy", opts.ReleaseName)
+}
+
+func (t *Suite) TestEnvSecretsAndConfigFiles() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`services:
+  consumer:
+    type: worker
+    env:
+      QUEUE: jobs
+      DATABASE_URL:
+        secret: PROD_DATABASE_URL
+    configFiles:
+      - path: consumer.toml
+        mountPath: /etc/consumer/consumer.toml
+`, map[string]string{"consumer.toml": "workers = 4\n"})
+	apps := additionalAppHelmOptions(jetCfg)
+	req.Len(apps, 1)
+	svcs := []builderService{apps[0].service}
+
+	secrets, err := envSecrets(svcs, map[string]string{"PROD_DATABASE_URL": "postgres://db"})
+	req.NoError(err)
+	req.Equal(map[string]string{"DATABASE_URL": "cG9zdGdyZXM6Ly9kYg=="}, secrets)
+	_, err = envSecrets(svcs, map[string]string{})
+	req.ErrorContains(err, "refers to secret PROD_DATABASE_URL, which is not set")
+
+	configMaps, err := configFilesConfigMaps(svcs)
+	req.NoError(err)
+	req.Len(configMaps, 1)
+	req.Equal("py-dockerfile-consumer-config-files", configMaps[0].Name)
+	req.Equal(map[string]string{"etc_consumer_consumer.toml": "workers = 4\n"}, configMaps[0].Data)
+}
+
+func (t *Suite) TestInternalServiceEnvCollision() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`services:
+  api:
+    type: web
+    env:
+      USERS_URL: http://localhost:8080
+  users:
+    type: internal
+    env:
+      USERS_PORT: "8080"
+`, nil)
+
+	// users doesn't get its own address, so only the env of api collides
+	_, err := internalServiceEnvVars(jetCfg, "my-ns", map[string]string{})
+	req.ErrorContains(
+		err,
+		"env USERS_URL of service api is set, but launchpad sets it to the address of internal service users",
+	)
+}
+
+func (t *Suite) TestEnvSecretsCollision() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`services:
+  consumer:
+    type: worker
+    env:
+      DATABASE_URL:
+        secret: PROD_DATABASE_URL
+  reporter:
+    type: worker
+    env:
+      DATABASE_URL:
+        secret: REPORTS_DATABASE_URL
+  auditor:
+    type: worker
+    env:
+      DATABASE_URL:
+        secret: PROD_DATABASE_URL
+`, nil)
+	svcs := map[string]builderService{}
+	for _, app := range additionalAppHelmOptions(jetCfg) {
+		svcs[app.service.GetName()] = app.service
+	}
+	remoteEnvVars := map[string]string{
+		"PROD_DATABASE_URL":    "postgres://db",
+		"REPORTS_DATABASE_URL": "postgres://reports",
+	}
+
+	// Services that are deployed together share their secrets, so one env var
+	// name can't refer to different secrets
+	_, err := envSecrets(
+		[]builderService{svcs["consumer"], svcs["reporter"]},
+		remoteEnvVars,
+	)
+	req.ErrorContains(
+		err,
+		"env DATABASE_URL of services consumer and reporter refers to different secrets",
+	)
+
+	secrets, err := envSecrets(
+		[]builderService{svcs["consumer"], svcs["auditor"]},
+		remoteEnvVars,
+	)
+	req.NoError(err)
+	req.Equal(map[string]string{"DATABASE_URL": "cG9zdGdyZXM6Ly9kYg=="}, secrets)
+}
+
+func (t *Suite) TestVolumeClaims() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`labels:
+  team: payments
+annotations:
+  example.com/owner: payments@example.com
+services:
+  api:
+    type: web
+  consumer:
+    type: worker
+    volumes:
+      - name: data
+        mountPath: /var/lib/consumer
+        size: 5Gi
+`, nil)
+
+	apps := additionalAppHelmOptions(jetCfg)
+	req.Len(apps, 1)
+	req.Equal(
+		[]*komponents.PersistentVolumeClaim{{
+			Name:        "py-dockerfile-consumer-data",
+			Size:        "5Gi",
+			AccessMode:  "ReadWriteOnce",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"example.com/owner": "payments@example.com"},
+		}},
+		volumeClaims([]builderService{apps[0].service}),
+	)
+	req.Empty(volumeClaims(mainAppServices(jetCfg)))
+}
+
+func (t *Suite) TestHelmServiceCharts() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`services:
+  redis:
+    type: helm
+    repo: https://charts.bitnami.com/bitnami
+    chart: redis
+    version: 17.11.3
+    values:
+      auth:
+        enabled: false
+    valuesFiles:
+      - redis.yaml
+  postgres:
+    type: helm
+    chart: oci://registry-1.docker.io/bitnamicharts/postgresql
+    namespace: db
+    releaseName: pg
+    wait: true
+`, map[string]string{
+		"redis.yaml": "architecture: standalone\nauth:\n  enabled: true\n  username: app\n",
+	})
+
+	charts := jetconfigHelmToChartConfig(jetCfg, "app-ns")
+	req.Len(charts, 2)
+	req.Equal(
+		&launchpad.ChartConfig{
+			Repo:      "https://charts.bitnami.com/bitnami",
+			Name:      "redis",
+			Namespace: "app-ns",
+			Release:   "redis-4pss8bs",
+			Timeout:   5 * time.Minute,
+			Version:   "17.11.3",
+		},
+		charts[0],
+	)
+	req.Equal(
+		&launchpad.ChartConfig{
+			Name:      "oci://registry-1.docker.io/bitnamicharts/postgresql",
+			Namespace: "db",
+			Release:   "pg",
+			Timeout:   5 * time.Minute,
+			Wait:      true,
+		},
+		charts[1],
+	)
+
+	values, err := helmChartValues(jetCfg.HelmCharts()[0])
+	req.NoError(err)
+	req.Equal(
+		map[string]any{
+			"architecture": "standalone",
+			"auth":         map[string]any{"enabled": false, "username": "app"},
+		},
+		values,
+	)
+	values, err = helmChartValues(jetCfg.HelmCharts()[1])
+	req.NoError(err)
+	req.Empty(values)
+}
+
+func (t *Suite) TestDomains() {
+	req := t.Require()
+	jetCfg := t.loadConfig(`ingress:
+  className: nginx
+  acmeEmail: ops@example.com
+labels:
+  team: payments
+services:
+  web:
+    type: web
+    domains: [example.com, www.example.com]
+  api:
+    type: web
+    port: 9000
+    domains: [api.example.com]
+  consumer:
+    type: worker
+    image: busybox:1.36
+`, nil)
+
+	unmanaged := mock.NewClusterForTest("my-cluster", false /*isLocal*/)
+	req.Equal(&launchpad.Domains{
+		Hostnames:        []string{"example.com", "www.example.com"},
+		IngressClassName: "nginx",
+		ACMEEmail:        "ops@example.com",
+		ServicePort:      8080,
+		Labels:           map[string]string{"team": "payments"},
+	}, mainAppDomains(jetCfg, unmanaged))
+
+	apps := additionalAppHelmOptions(jetCfg)
+	req.Len(apps, 2)
+	api := domains(jetCfg, apps[0].service.(jetconfig.Web), unmanaged)
+	req.Equal([]string{"api.example.com"}, api.Hostnames)
+	req.Equal(9000, api.ServicePort)
+	req.False(api.Ambassador)
+
+	managed := mock.NewJetpackManagedClusterForTest(jetCfg.Cluster, "cluster.jetpack.dev")
+	req.True(mainAppDomains(jetCfg, managed).Ambassador)
+	req.Nil(mainAppDomains(jetCfg, mock.NewClusterForTest("kind", true /*isLocal*/)))
+}
@@ -3,6 +3,7 @@ package flags
 import (
 	"strings"
 
+	"go.jetpack.io/launchpad/pkg/kubevalidate"
 	"go.jetpack.io/launchpad/proto/api"
 )
 
@@ -12,10 +13,21 @@ type RootCmdFlags struct {
 	SkipVersionCheck bool
 }
 
+// IsValidEnvironment returns true if the environment flag is a valid
+// environment name. Whether a non built-in environment is declared in
+// launchpad.yaml is checked when the config is loaded.
 func (f *RootCmdFlags) IsValidEnvironment() bool {
-	return f.Env() != api.Environment_NONE
+	return kubevalidate.IsValidRFC1123Name(f.EnvName())
 }
 
+// Env returns the built-in environment, or api.Environment_NONE if the selected
+// environment is one declared in launchpad.yaml.
 func (f *RootCmdFlags) Env() api.Environment {
 	return api.Environment(api.Environment_value[strings.ToUpper(f.Environment)])
 }
+
+// EnvName returns the name of the selected environment, which is either a
+// built-in environment or one declared in launchpad.yaml.
+func (f *RootCmdFlags) EnvName() string {
+	return strings.ToLower(f.Environment)
+}
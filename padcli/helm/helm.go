@@ -7,10 +7,13 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
+	"go.jetpack.io/launchpad/goutil"
 	"go.jetpack.io/launchpad/padcli/jetconfig"
 	"go.jetpack.io/launchpad/padcli/provider"
-	"go.jetpack.io/launchpad/proto/api"
+	"go.jetpack.io/launchpad/pkg/reaktor/komponents"
 )
 
 // ValueComputer transforms jetpack CLI inputs into helm values.
@@ -20,7 +23,16 @@ type ValueComputer struct {
 	appValues     map[string]any
 	runtimeValues map[string]any
 
-	env                 api.Environment
+	// Values for web services beyond the first, internal services and
+	// workers, keyed by service name. Each of these is installed as its own
+	// release of the app chart.
+	additionalAppValues map[string]map[string]any
+
+	// Values for the release of the app chart that runs pre-deploy jobs. Nil
+	// if there are none.
+	preDeployValues map[string]any
+
+	env                 string // built-in or declared in launchpad.yaml
 	namespace           string // The final namespace to be used
 	createNamespace     bool   // Value used for helm's --create-namespace
 	execQualifiedSymbol string // Used by jetpack dev <path/to/project> --exec <symbol>
@@ -30,7 +42,7 @@ type ValueComputer struct {
 }
 
 func NewValueComputer(
-	env api.Environment,
+	env string,
 	namespace string,
 	execQualifiedSymbol string,
 	imageProvider *ImageProvider,
@@ -55,6 +67,28 @@ func (hvc *ValueComputer) RuntimeValues() map[string]any {
 	return hvc.runtimeValues
 }
 
+// AdditionalAppValues returns the app chart values for every web service
+// except the first one, and for every internal service and worker, keyed by
+// service name.
+func (hvc *ValueComputer) AdditionalAppValues() map[string]map[string]any {
+	return hvc.additionalAppValues
+}
+
+// PreDeployValues returns the app chart values for the release that runs the
+// pre-deploy jobs, or nil if there are none.
+func (hvc *ValueComputer) PreDeployValues() map[string]any {
+	return hvc.preDeployValues
+}
+
+// WithAppValues returns a copy of hvc whose AppValues are values, e.g. those
+// of an additional app release, so that hooks that change the app chart values
+// can run for every release of the app chart.
+func (hvc *ValueComputer) WithAppValues(values map[string]any) *ValueComputer {
+	c := *hvc
+	c.appValues = values
+	return &c
+}
+
 func (hvc *ValueComputer) ImageProvider() *ImageProvider {
 	return hvc.imageProvider
 }
@@ -63,31 +97,20 @@ func (hvc *ValueComputer) ImageProvider() *ImageProvider {
 func (hvc *ValueComputer) Compute(ctx context.Context) error {
 	hvc.appValues = map[string]any{}
 	hvc.runtimeValues = map[string]any{}
-
-	websvc, err := hvc.jetCfg.WebService()
-	if err != nil {
-		return errors.WithStack(err)
+	hvc.additionalAppValues = map[string]map[string]any{}
+	hvc.preDeployValues = nil
+
+	// The first web service is deployed as part of the main app release, along
+	// with the cronjobs and jobs. Any other web service gets its own release.
+	var websvc jetconfig.Web
+	websvcs := hvc.jetCfg.WebServices()
+	if len(websvcs) > 0 {
+		websvc = websvcs[0]
 	}
 
 	if hvc.cluster.IsJetpackManaged() {
 		SetNestedField(hvc.appValues, "jetpack", "clusterHostname", hvc.cluster.GetHostname())
 		SetNestedField(hvc.runtimeValues, "jetpack", "clusterHostname", hvc.cluster.GetHostname())
-
-		if websvc != nil {
-			hostname, err := hvc.ComputeHostname(ctx)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			SetNestedField(hvc.appValues, "ambassador", "hostname", hostname)
-
-			url, err := websvc.GetURL()
-			if err != nil {
-				return errors.Wrap(err, "unable to get web service url")
-			}
-			if url.Path != "" {
-				SetNestedField(hvc.appValues, "ambassador", "urlPrefix", url.Path)
-			}
-		}
 	}
 
 	if hvc.execQualifiedSymbol != "" {
@@ -97,86 +120,528 @@ func (hvc *ValueComputer) Compute(ctx context.Context) error {
 	SetNestedField(hvc.appValues, "jetpack", "cronjobs", lo.Map(
 		hvc.jetCfg.Cronjobs(),
 		func(cj jetconfig.Cron, _ int) any {
-			return map[string]any{
+			values := map[string]any{
 				"name":              ToValidName(cj.GetUniqueName()),
 				"schedule":          cj.GetSchedule(),
 				"concurrencyPolicy": cj.GetConcurrencyPolicy(),
-				"image":             hvc.imageProvider.get(hvc.cluster, cj.GetImage()),
+				"image":             hvc.imageProvider.getForService(hvc.cluster, cj),
 				"command":           cj.GetCommand(),
-				"resources": map[string]any{
-					"requests": map[string]any{
-						"cpu":    cj.GetInstanceType().Compute(),
-						"memory": cj.GetInstanceType().Memory(),
-					},
-				},
+				"resources":         cj.GetResources().Values(),
+				"suspend":           cj.IsSuspended(),
 			}
-		},
-	))
-
-	SetNestedField(hvc.appValues, "jetpack", "jobs", lo.Map(
-		hvc.jetCfg.Jobs(),
-		func(j jetconfig.Job, _ int) any {
-			return map[string]any{
-				"name":    ToValidName(j.GetUniqueName()),
-				"image":   hvc.imageProvider.get(hvc.cluster, j.GetImage()),
-				"command": j.GetCommand(),
-				"resources": map[string]any{
-					"requests": map[string]any{
-						"cpu":    j.GetInstanceType().Compute(),
-						"memory": j.GetInstanceType().Memory(),
-					},
-				},
+			if tz := cj.GetTimeZone(); tz != "" {
+				values["timeZone"] = tz
+			}
+			for name, v := range map[string]*int{
+				"startingDeadlineSeconds":    cj.GetStartingDeadlineSeconds(),
+				"successfulJobsHistoryLimit": cj.GetSuccessfulJobsHistoryLimit(),
+				"failedJobsHistoryLimit":     cj.GetFailedJobsHistoryLimit(),
+			} {
+				if v != nil {
+					values[name] = *v
+				}
 			}
+			setContainerValues(values, cj)
+			return values
 		},
 	))
 
+	isPreDeploy := func(j jetconfig.Job, _ int) bool { return j.IsPreDeploy() }
+	preDeployJobs := lo.Filter(hvc.jetCfg.Jobs(), isPreDeploy)
+	SetNestedField(
+		hvc.appValues,
+		"jetpack",
+		"jobs",
+		hvc.jobValues(lo.Reject(hvc.jetCfg.Jobs(), isPreDeploy)),
+	)
+
 	SetNestedField(hvc.appValues, "jetpack", "projectId", hvc.jetCfg.GetProjectID())
+	hvc.setCommonMetadataValues(hvc.appValues)
 
 	// A bit lame but required because technically can be nil.
 	if websvc != nil {
-		setNestedFieldPath(
-			hvc.appValues,
-			[]string{"resources", "requests", "cpu"},
-			websvc.GetInstanceType().Compute(),
-		)
-
-		setNestedFieldPath(
-			hvc.appValues,
-			[]string{"resources", "requests", "memory"},
-			websvc.GetInstanceType().Memory(),
-		)
-
-		hvc.appValues["podPort"] = websvc.GetPort()
+		if err := hvc.computeWebServiceValues(ctx, hvc.appValues, websvc); err != nil {
+			return errors.WithStack(err)
+		}
 	} else {
-		// This logic will change once we allow multiple services
 		hvc.appValues["replicaCount"] = 0
 		SetNestedField(hvc.appValues, "serviceAccount", "create", false)
+
+		if hvc.cluster.IsLocal() {
+			SetNestedField(hvc.appValues, "service", "type", "NodePort")
+		}
+
+		// Legacy configs don't have an explicit web service. We might still need
+		// to set image values in case it's a legacy config that publishes an image.
+		repo, tag := hvc.imageProvider.getSplit(hvc.cluster, "")
+		hvc.appValues["image"] = map[string]any{
+			"repository": repo,
+			"tag":        tag,
+		}
 	}
 
-	if hvc.cluster.IsLocal() {
-		SetNestedField(hvc.appValues, "service", "type", "NodePort")
+	for _, w := range lo.Drop(websvcs, 1) {
+		values := hvc.newAdditionalAppValues()
+		if err := hvc.computeWebServiceValues(ctx, values, w); err != nil {
+			return errors.WithStack(err)
+		}
+		hvc.additionalAppValues[w.GetName()] = values
 	}
 
-	// Legacy configs don't have an explicit web service. We might still need
-	// to set image values in case it's a legacy config that publishes an image.
-	configImage := ""
-	if websvc != nil {
-		configImage = websvc.GetImage()
+	for _, i := range hvc.jetCfg.InternalServices() {
+		values := hvc.newAdditionalAppValues()
+		hvc.computeInternalServiceValues(values, i)
+		hvc.additionalAppValues[i.GetName()] = values
+	}
+
+	for _, w := range hvc.jetCfg.Workers() {
+		values := hvc.newAdditionalAppValues()
+		hvc.computeWorkerValues(values, w)
+		hvc.additionalAppValues[w.GetName()] = values
+	}
+
+	if len(preDeployJobs) > 0 {
+		// A release with only jobs: no deployment, service or ingress.
+		hvc.preDeployValues = hvc.newAdditionalAppValues()
+		SetNestedField(hvc.preDeployValues, "jetpack", "jobs", hvc.jobValues(preDeployJobs))
+		SetNestedField(hvc.preDeployValues, "deployment", "enabled", false)
+		SetNestedField(hvc.preDeployValues, "service", "enabled", false)
+		SetNestedField(hvc.preDeployValues, "ambassador", "enabled", false)
+		repo, tag := hvc.imageProvider.getSplit(hvc.cluster, "")
+		hvc.preDeployValues["image"] = map[string]any{
+			"repository": repo,
+			"tag":        tag,
+		}
+	}
+
+	return nil
+}
+
+func (hvc *ValueComputer) jobValues(jobs []jetconfig.Job) []any {
+	return lo.Map(jobs, func(j jetconfig.Job, _ int) any {
+		values := map[string]any{
+			"name":      ToValidName(j.GetUniqueName()),
+			"image":     hvc.imageProvider.getForService(hvc.cluster, j),
+			"command":   j.GetCommand(),
+			"resources": j.GetResources().Values(),
+		}
+		// Unset fields are left to the chart, which uses the release's
+		// jobs.ttlSecondsAfterFinished and the kubernetes defaults.
+		for name, v := range map[string]*int{
+			"backoffLimit":            j.GetBackoffLimit(),
+			"activeDeadlineSeconds":   j.GetActiveDeadlineSeconds(),
+			"parallelism":             j.GetParallelism(),
+			"completions":             j.GetCompletions(),
+			"ttlSecondsAfterFinished": j.GetTTLSecondsAfterFinished(),
+		} {
+			if v != nil {
+				values[name] = *v
+			}
+		}
+		setContainerValues(values, j)
+		return values
+	})
+}
+
+// ConfigFilesName returns the name of the ConfigMap that holds the config
+// files of a service.
+func ConfigFilesName(svc jetconfig.Service) string {
+	return ToValidName(svc.GetUniqueName()) + "-config-files"
+}
+
+type builderService interface {
+	jetconfig.Builder
+	jetconfig.Service
+}
+
+// setContainerValues sets the plain environment variables, config file mounts,
+// volumes, sidecars, init containers, scheduling, labels and annotations of a
+// service. Env vars that refer to secrets are added to the release's secrets
+// when deploying, since their values are not known here.
+func setContainerValues(values map[string]any, svc builderService) {
+	env := svc.GetEnv()
+	names := maps.Keys(env)
+	slices.Sort(names)
+	envValues := []any{}
+	for _, name := range names {
+		if env[name].Secret == "" {
+			envValues = append(envValues, map[string]any{
+				"name":  name,
+				"value": env[name].Value,
+			})
+		}
+	}
+	if len(envValues) > 0 {
+		values["env"] = envValues
+	}
+
+	volumes := []map[string]any{}
+	volumeMounts := []map[string]any{}
+	if files := svc.GetConfigFiles(); len(files) > 0 {
+		ref := &komponents.ConfigRef{ConfigMapRef: ConfigFilesName(svc)}
+		volumes = append(volumes, ref.ToVolumes()...)
+		volumeMounts = append(volumeMounts, ref.ToFileVolumeMounts(lo.SliceToMap(
+			files,
+			func(f jetconfig.ConfigFile) (string, string) { return f.MountPath, f.Key() },
+		))...)
+	}
+	if s, ok := svc.(interface{ GetVolumes() []jetconfig.Volume }); ok {
+		for _, v := range s.GetVolumes() {
+			volumes = append(volumes, map[string]any{
+				"name": "volume-" + v.Name,
+				"persistentVolumeClaim": map[string]any{
+					"claimName": VolumeClaimName(svc, v),
+				},
+			})
+			volumeMounts = append(volumeMounts, map[string]any{
+				"name":      "volume-" + v.Name,
+				"mountPath": v.MountPath,
+			})
+		}
+	}
+	if len(volumes) > 0 {
+		values["volumes"] = volumes
+		values["volumeMounts"] = volumeMounts
+	}
+
+	if initContainers := svc.GetInitContainers(); len(initContainers) > 0 {
+		values["initContainers"] = lo.Map(initContainers, containerValues)
+	}
+	if sidecars := svc.GetSidecars(); len(sidecars) > 0 {
+		values["sidecars"] = lo.Map(sidecars, containerValues)
+	}
+
+	setSchedulingValues(values, svc)
+
+	// The chart adds these to the service's Deployment, Service, CronJob or Job,
+	// and to its pod template.
+	if labels := svc.GetLabels(); len(labels) > 0 {
+		values["labels"] = labels
+	}
+	if annotations := svc.GetAnnotations(); len(annotations) > 0 {
+		values["annotations"] = annotations
+	}
+}
+
+// setCommonMetadataValues sets the project's labels and annotations, which the
+// chart adds to every resource of a release, including the ones shared by its
+// services such as the Secret.
+func (hvc *ValueComputer) setCommonMetadataValues(values map[string]any) {
+	if labels := hvc.jetCfg.GetLabels(); len(labels) > 0 {
+		values["commonLabels"] = labels
+	}
+	if annotations := hvc.jetCfg.GetAnnotations(); len(annotations) > 0 {
+		values["commonAnnotations"] = annotations
+	}
+}
+
+// jobNameLabel is set by kubernetes on the pods of a job
+const jobNameLabel = "batch.kubernetes.io/job-name"
+
+// setSchedulingValues sets the node selector, tolerations, affinity and
+// topology spread constraints of a service's pods.
+func setSchedulingValues(values map[string]any, svc builderService) {
+	scheduling := svc.GetScheduling()
+	if nodeSelector := scheduling.GetNodeSelector(); len(nodeSelector) > 0 {
+		values["nodeSelector"] = nodeSelector
+	}
+	if tolerations := scheduling.GetTolerations(); len(tolerations) > 0 {
+		values["tolerations"] = lo.Map(
+			tolerations,
+			func(t jetconfig.Toleration, _ int) map[string]any {
+				toleration := lo.MapValues(
+					lo.OmitByValues(map[string]string{
+						"key":      t.Key,
+						"operator": t.Operator,
+						"value":    t.Value,
+						"effect":   t.Effect,
+					}, []string{""}),
+					func(v string, _ string) any { return v },
+				)
+				if t.TolerationSeconds != nil {
+					toleration["tolerationSeconds"] = *t.TolerationSeconds
+				}
+				return toleration
+			},
+		)
+	}
+	if len(scheduling.Affinity) > 0 {
+		values["affinity"] = scheduling.Affinity
 	}
-	repo, tag := hvc.imageProvider.getSplit(hvc.cluster, configImage)
-	hvc.appValues["image"] = map[string]any{
+	if len(scheduling.TopologySpread) == 0 {
+		return
+	}
+
+	// By default, spread the pods of the service: the pods of its release for
+	// deployments, and the pods of each job for jobs and cronjobs.
+	defaultSelector := map[string]any{
+		"matchLabels": map[string]any{
+			"app.kubernetes.io/instance": ToValidName(svc.GetUniqueName()),
+		},
+	}
+	var matchLabelKeys []string
+	switch svc.(type) {
+	case jetconfig.Job, jetconfig.Cron:
+		defaultSelector = map[string]any{
+			"matchExpressions": []any{
+				map[string]any{"key": jobNameLabel, "operator": "Exists"},
+			},
+		}
+		matchLabelKeys = []string{jobNameLabel}
+	}
+	values["topologySpreadConstraints"] = lo.Map(
+		scheduling.TopologySpread,
+		func(t jetconfig.TopologySpread, _ int) map[string]any {
+			constraint := map[string]any{
+				"maxSkew":           t.GetMaxSkew(),
+				"topologyKey":       t.TopologyKey,
+				"whenUnsatisfiable": t.GetWhenUnsatisfiable(),
+				"labelSelector":     defaultSelector,
+			}
+			if len(t.LabelSelector) > 0 {
+				constraint["labelSelector"] = map[string]any{"matchLabels": t.LabelSelector}
+			} else if len(matchLabelKeys) > 0 {
+				constraint["matchLabelKeys"] = matchLabelKeys
+			}
+			return constraint
+		},
+	)
+}
+
+// containerValues returns the Kubernetes container spec of a sidecar or init
+// container.
+func containerValues(c jetconfig.Container, _ int) map[string]any {
+	values := map[string]any{
+		"name":  c.Name,
+		"image": c.Image,
+	}
+	if len(c.Command) > 0 {
+		values["command"] = c.Command
+	}
+	if len(c.Env) > 0 {
+		names := maps.Keys(c.Env)
+		slices.Sort(names)
+		values["env"] = lo.Map(names, func(name string, _ int) map[string]any {
+			return map[string]any{"name": name, "value": c.Env[name]}
+		})
+	}
+	if len(c.Ports) > 0 {
+		values["ports"] = lo.Map(c.Ports, func(port int, _ int) map[string]any {
+			return map[string]any{"containerPort": port}
+		})
+	}
+	if resources := c.Resources.Values(); len(resources) > 0 {
+		values["resources"] = resources
+	}
+	return values
+}
+
+// VolumeClaimName returns the name of the PersistentVolumeClaim that backs a
+// volume of a service.
+func VolumeClaimName(svc jetconfig.Service, v jetconfig.Volume) string {
+	return ToValidName(svc.GetUniqueName()) + "-" + v.Name
+}
+
+// newAdditionalAppValues returns the values shared by every app release other
+// than the main one.
+func (hvc *ValueComputer) newAdditionalAppValues() map[string]any {
+	values := map[string]any{}
+	if hvc.cluster.IsJetpackManaged() {
+		SetNestedField(values, "jetpack", "clusterHostname", hvc.cluster.GetHostname())
+	}
+	SetNestedField(values, "jetpack", "projectId", hvc.jetCfg.GetProjectID())
+	hvc.setCommonMetadataValues(values)
+	// Cronjobs and jobs belong to the main app release only.
+	SetNestedField(values, "jetpack", "cronjobs", []any{})
+	SetNestedField(values, "jetpack", "jobs", []any{})
+	return values
+}
+
+// computeInternalServiceValues sets the values that describe a single internal
+// service on an app chart release. Internal services get a ClusterIP service,
+// but no ingress.
+func (hvc *ValueComputer) computeInternalServiceValues(
+	values map[string]any,
+	i jetconfig.Internal,
+) {
+	SetNestedField(values, "service", "type", "ClusterIP")
+	SetNestedField(values, "ambassador", "enabled", false)
+
+	values["resources"] = i.GetResources().Values()
+	setContainerValues(values, i)
+	setHealthCheckValues(values, i.GetHealthCheck(), i.GetPort())
+
+	values["podPort"] = i.GetPort()
+
+	repo, tag := hvc.imageProvider.getSplitForService(hvc.cluster, i)
+	values["image"] = map[string]any{
+		"repository": repo,
+		"tag":        tag,
+	}
+}
+
+// computeWorkerValues sets the values that describe a single worker on an app
+// chart release. Workers are deployments without a service or ingress.
+func (hvc *ValueComputer) computeWorkerValues(
+	values map[string]any,
+	w jetconfig.Worker,
+) {
+	SetNestedField(values, "service", "enabled", false)
+	SetNestedField(values, "ambassador", "enabled", false)
+
+	values["resources"] = w.GetResources().Values()
+	setContainerValues(values, w)
+	setHealthCheckValues(values, w.GetHealthCheck(), 0)
+
+	values["replicaCount"] = w.GetReplicas()
+	if command := w.GetCommand(); len(command) > 0 {
+		values["command"] = command
+	}
+
+	repo, tag := hvc.imageProvider.getSplitForService(hvc.cluster, w)
+	values["image"] = map[string]any{
 		"repository": repo,
 		"tag":        tag,
 	}
+}
 
+// computeWebServiceValues sets the values that describe a single web service
+// (deployment, service, ingress and image) on an app chart release.
+func (hvc *ValueComputer) computeWebServiceValues(
+	ctx context.Context,
+	values map[string]any,
+	websvc jetconfig.Web,
+) error {
+	if hvc.cluster.IsJetpackManaged() {
+		hostname, err := hvc.ComputeHostname(ctx, websvc)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		SetNestedField(values, "ambassador", "hostname", hostname)
+
+		url, err := websvc.GetURL()
+		if err != nil {
+			return errors.Wrap(err, "unable to get web service url")
+		}
+		if url.Path != "" {
+			SetNestedField(values, "ambassador", "urlPrefix", url.Path)
+		}
+	}
+
+	values["resources"] = websvc.GetResources().Values()
+	setContainerValues(values, websvc)
+	setHealthCheckValues(values, websvc.GetHealthCheck(), websvc.GetPort())
+
+	values["podPort"] = websvc.GetPort()
+
+	if replicas := websvc.GetReplicas(); replicas.IsAutoscaled() {
+		// launchpad creates the HPA itself, with an apiVersion that the cluster
+		// supports. It sets replicaCount to the deployment's current replicas
+		// when it deploys, so that upgrades don't scale it back down.
+		SetNestedField(values, "autoscaling", "enabled", false)
+	} else if replicas != nil {
+		values["replicaCount"] = replicas.Min
+	}
+
+	if hvc.cluster.IsLocal() {
+		SetNestedField(values, "service", "type", "NodePort")
+	}
+
+	repo, tag := hvc.imageProvider.getSplitForService(hvc.cluster, websvc)
+	values["image"] = map[string]any{
+		"repository": repo,
+		"tag":        tag,
+	}
 	return nil
 }
 
-func (hvc *ValueComputer) ComputeHostname(ctx context.Context) (string, error) {
-	websvc, err := hvc.jetCfg.WebService()
-	if err != nil {
-		return "", errors.WithStack(err)
+// Kubernetes probe defaults, used when the health check leaves them unset.
+const (
+	defaultProbePeriodSeconds    = 10
+	defaultProbeFailureThreshold = 3
+)
+
+// minLivenessDelaySeconds is the least time a service without startupSeconds
+// gets to start before liveness checks can restart it.
+const minLivenessDelaySeconds = 30
+
+// setHealthCheckValues sets the readiness and liveness probes of an app chart
+// release from a service's health check, plus a startup probe if the service
+// may take a while to start. Liveness tolerates twice as many failures as
+// readiness, so that a briefly overloaded pod stops receiving traffic before
+// it's restarted. Checks without a port use port.
+func setHealthCheckValues(values map[string]any, hc *jetconfig.HealthCheck, port int) {
+	if hc == nil {
+		return
+	}
+
+	if hc.StartupSeconds > 0 {
+		period := goutil.Coalesce(hc.PeriodSeconds, defaultProbePeriodSeconds)
+		values["startupProbe"] = lo.Assign(probeValues(hc, port), map[string]any{
+			"periodSeconds": period,
+			// round up, so that the service gets at least StartupSeconds
+			"failureThreshold": (hc.StartupSeconds + period - 1) / period,
+		})
+	}
+
+	readiness := probeValues(hc, port)
+	if hc.InitialDelaySeconds > 0 {
+		readiness["initialDelaySeconds"] = hc.InitialDelaySeconds
+	}
+	if hc.PeriodSeconds > 0 {
+		readiness["periodSeconds"] = hc.PeriodSeconds
 	}
+	if hc.FailureThreshold > 0 {
+		readiness["failureThreshold"] = hc.FailureThreshold
+	}
+	values["readinessProbe"] = readiness
+
+	liveness := probeValues(hc, port)
+	if hc.StartupSeconds > 0 {
+		// the startup probe holds off liveness checks until the service is up
+		if hc.InitialDelaySeconds > 0 {
+			liveness["initialDelaySeconds"] = hc.InitialDelaySeconds
+		}
+	} else {
+		liveness["initialDelaySeconds"] = lo.Max(
+			[]int{hc.InitialDelaySeconds, minLivenessDelaySeconds},
+		)
+	}
+	if hc.PeriodSeconds > 0 {
+		liveness["periodSeconds"] = hc.PeriodSeconds
+	}
+	liveness["failureThreshold"] = 2 *
+		goutil.Coalesce(hc.FailureThreshold, defaultProbeFailureThreshold)
+	values["livenessProbe"] = liveness
+}
+
+// probeValues returns a new probe with the check and timeout of hc.
+func probeValues(hc *jetconfig.HealthCheck, port int) map[string]any {
+	probe := map[string]any{}
+	switch {
+	case hc.HTTP != nil:
+		probe["httpGet"] = map[string]any{
+			"path": goutil.Coalesce(hc.HTTP.Path, "/"),
+			"port": goutil.Coalesce(hc.HTTP.Port, port),
+		}
+	case hc.TCP != nil:
+		probe["tcpSocket"] = map[string]any{
+			"port": goutil.Coalesce(hc.TCP.Port, port),
+		}
+	default:
+		probe["exec"] = map[string]any{"command": hc.Exec}
+	}
+	if hc.TimeoutSeconds > 0 {
+		probe["timeoutSeconds"] = hc.TimeoutSeconds
+	}
+	return probe
+}
+
+// ComputeHostname returns the public hostname of the given web service.
+func (hvc *ValueComputer) ComputeHostname(
+	ctx context.Context,
+	websvc jetconfig.Web,
+) (string, error) {
 	hostname := websvc.GetName() + "-" + hvc.namespace + "." + hvc.cluster.GetHostname()
 	url, err := websvc.GetURL()
 	if err != nil {
@@ -195,18 +660,23 @@ func ToValidName(name string) string {
 	return strings.ToLower(name)
 }
 
+// RequiresCustomHost returns true if any web service is served on a hostname
+// outside of the cluster's own domain.
 func (hvc *ValueComputer) RequiresCustomHost(ctx context.Context) (bool, error) {
-	if websvc, err := hvc.jetCfg.WebService(); websvc == nil {
-		return false, err
-	}
-	h, err := hvc.ComputeHostname(ctx)
-	if err != nil {
-		return false, err
+	for _, websvc := range hvc.jetCfg.WebServices() {
+		h, err := hvc.ComputeHostname(ctx, websvc)
+		if err != nil {
+			return false, err
+		}
+		if !strings.HasSuffix(h, "."+hvc.cluster.GetHostname()) {
+			return true, nil
+		}
 	}
-	return !strings.HasSuffix(h, "."+hvc.cluster.GetHostname()), nil
+	return false, nil
 }
 
-func (hvc *ValueComputer) Environment() api.Environment {
+// Environment returns the name of the selected environment.
+func (hvc *ValueComputer) Environment() string {
 	return hvc.env
 }
 
@@ -0,0 +1,735 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.jetpack.io/launchpad/padcli/jetconfig"
+	"go.jetpack.io/launchpad/padcli/provider"
+)
+
+// computeValues computes the helm values of a launchpad.yaml for the dev
+// environment. images may be nil if no images were built.
+func (s *Suite) computeValues(
+	yamlContents string,
+	namespace string,
+	cluster provider.Cluster,
+	images *ImageProvider,
+) *ValueComputer {
+	req := s.Require()
+	dir := s.T().TempDir()
+	req.NoError(os.WriteFile(filepath.Join(dir, "launchpad.yaml"), []byte(yamlContents), 0644))
+	ctx := context.Background()
+	jetCfg, err := jetconfig.RequireFromFileSystem(ctx, dir, "dev")
+	req.NoError(err)
+	if images == nil {
+		images = NewImageProvider("", nil, nil, "")
+	}
+
+	hvc := NewValueComputer(
+		"dev",
+		namespace,
+		"", // execQualifiedSymbol
+		images,
+		jetCfg,
+		cluster,
+	)
+	req.NoError(hvc.Compute(ctx))
+	return hvc
+}
+
+// projectYAML are the launchpad.yaml fields that TestServiceValues cases
+// share.
+const projectYAML = `configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+`
+
+// A release picks the values that a TestServiceValues case checks.
+type release func(hvc *ValueComputer) map[string]any
+
+func appRelease(hvc *ValueComputer) map[string]any {
+	return hvc.AppValues()
+}
+
+func preDeployRelease(hvc *ValueComputer) map[string]any {
+	return hvc.PreDeployValues()
+}
+
+func additionalRelease(name string) release {
+	return func(hvc *ValueComputer) map[string]any {
+		return hvc.AdditionalAppValues()[name]
+	}
+}
+
+// jobs returns the jobs of r by name.
+func jobs(r release) release {
+	return jetpackList(r, "jobs")
+}
+
+// cronjobs returns the cronjobs of r by name.
+func cronjobs(r release) release {
+	return jetpackList(r, "cronjobs")
+}
+
+func jetpackList(r release, key string) release {
+	return func(hvc *ValueComputer) map[string]any {
+		byName := map[string]any{}
+		for _, v := range r(hvc)["jetpack"].(map[string]any)[key].([]any) {
+			byName[v.(map[string]any)["name"].(string)] = v
+		}
+		return byName
+	}
+}
+
+// requireValues requires values to have the expected value for every key of
+// expected. Maps are compared key by key, and nil requires the key to be
+// unset.
+func (s *Suite) requireValues(expected, values map[string]any, path string) {
+	req := s.Require()
+	for key, want := range expected {
+		keyPath := path + "." + key
+		got, ok := values[key]
+		if want == nil {
+			req.False(ok, "%s is set", keyPath)
+			continue
+		}
+		req.True(ok, "%s is not set", keyPath)
+		wantMap, wantIsMap := want.(map[string]any)
+		gotMap, gotIsMap := got.(map[string]any)
+		if wantIsMap && gotIsMap {
+			s.requireValues(wantMap, gotMap, keyPath)
+			continue
+		}
+		req.Equal(want, got, keyPath)
+	}
+}
+
+func (s *Suite) TestSetHealthCheckValues() {
+	req := s.Require()
+
+	cases := []struct {
+		name string
+		hc   *jetconfig.HealthCheck
+		want map[string]any
+	}{
+		{"none", nil, map[string]any{}},
+		{
+			"http",
+			&jetconfig.HealthCheck{
+				HTTP:                &jetconfig.HTTPHealthCheck{Path: "/healthz"},
+				InitialDelaySeconds: 5,
+			},
+			map[string]any{
+				"readinessProbe": map[string]any{
+					"httpGet":             map[string]any{"path": "/healthz", "port": 8080},
+					"initialDelaySeconds": 5,
+				},
+				"livenessProbe": map[string]any{
+					"httpGet":             map[string]any{"path": "/healthz", "port": 8080},
+					"initialDelaySeconds": 30,
+					"failureThreshold":    6,
+				},
+			},
+		},
+		{
+			"tcp with startup",
+			&jetconfig.HealthCheck{
+				TCP:            &jetconfig.TCPHealthCheck{Port: 9000},
+				PeriodSeconds:  20,
+				StartupSeconds: 90,
+			},
+			map[string]any{
+				"startupProbe": map[string]any{
+					"tcpSocket":        map[string]any{"port": 9000},
+					"periodSeconds":    20,
+					"failureThreshold": 5,
+				},
+				"readinessProbe": map[string]any{
+					"tcpSocket":     map[string]any{"port": 9000},
+					"periodSeconds": 20,
+				},
+				"livenessProbe": map[string]any{
+					"tcpSocket":        map[string]any{"port": 9000},
+					"periodSeconds":    20,
+					"failureThreshold": 6,
+				},
+			},
+		},
+		{
+			"exec",
+			&jetconfig.HealthCheck{Exec: []string{"cat", "/tmp/ready"}, TimeoutSeconds: 2},
+			map[string]any{
+				"readinessProbe": map[string]any{
+					"exec":           map[string]any{"command": []string{"cat", "/tmp/ready"}},
+					"timeoutSeconds": 2,
+				},
+				"livenessProbe": map[string]any{
+					"exec":                map[string]any{"command": []string{"cat", "/tmp/ready"}},
+					"timeoutSeconds":      2,
+					"initialDelaySeconds": 30,
+					"failureThreshold":    6,
+				},
+			},
+		},
+		{
+			"thresholds",
+			&jetconfig.HealthCheck{
+				HTTP:                &jetconfig.HTTPHealthCheck{Port: 9090},
+				InitialDelaySeconds: 45,
+				FailureThreshold:    2,
+			},
+			map[string]any{
+				"readinessProbe": map[string]any{
+					"httpGet":             map[string]any{"path": "/", "port": 9090},
+					"initialDelaySeconds": 45,
+					"failureThreshold":    2,
+				},
+				"livenessProbe": map[string]any{
+					"httpGet":             map[string]any{"path": "/", "port": 9090},
+					"initialDelaySeconds": 45,
+					"failureThreshold":    4,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		values := map[string]any{}
+		setHealthCheckValues(values, tc.hc, 8080)
+		req.Equal(tc.want, values, tc.name)
+		if tc.hc != nil {
+			// changing one probe mustn't change the others
+			values["readinessProbe"].(map[string]any)["failureThreshold"] = 100
+			req.NotEqual(100, values["livenessProbe"].(map[string]any)["failureThreshold"])
+		}
+	}
+}
+
+func (s *Suite) TestReplicaValues() {
+	req := s.Require()
+	hvc := s.computeValues(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  web:
+    type: web
+    replicas: 3
+  api:
+    type: web
+    replicas:
+      min: 2
+      max: 10
+      targetCPU: 70
+`, "my-ns", provider.KubeConfigCluster("", false, "my-cluster", false), nil)
+
+	web := hvc.AppValues()
+	req.Equal(3, web["replicaCount"])
+	req.NotContains(web, "autoscaling")
+
+	// The HPA controls the replicas of services that autoscale
+	api := hvc.AdditionalAppValues()["api"]
+	req.NotContains(api, "replicaCount")
+	req.Equal(map[string]any{"enabled": false}, api["autoscaling"])
+}
+
+func (s *Suite) TestWithAppValues() {
+	req := s.Require()
+	hvc := s.computeValues(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  web:
+    type: web
+  consumer:
+    type: worker
+`, "my-ns", provider.KubeConfigCluster("", false, "my-cluster", false), nil)
+
+	web := hvc.AppValues()
+	consumer := hvc.AdditionalAppValues()["consumer"]
+	req.NotNil(consumer)
+
+	// Hooks that change the app chart values see the values of the release
+	// they run for
+	worker := hvc.WithAppValues(consumer)
+	req.Equal(consumer, worker.AppValues())
+	req.Equal("my-ns", worker.Namespace())
+	req.Equal(web, hvc.AppValues())
+}
+
+func (s *Suite) TestMultipleWebServiceValues() {
+	req := s.Require()
+	hvc := s.computeValues(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+  admin:
+    type: web
+    port: 3000
+`, "my-ns", provider.KubeConfigCluster("cluster.jetpack.dev", true, "my-cluster", false), nil)
+
+	// The first web service is deployed by the main app release
+	api := hvc.AppValues()
+	req.Equal(
+		map[string]any{"hostname": "api-my-ns.cluster.jetpack.dev"},
+		api["ambassador"],
+	)
+	req.Equal(jetconfig.DefaultAppPodPort, api["podPort"])
+
+	// Every other one by its own release
+	req.Equal(
+		map[string]map[string]any{
+			"admin": {
+				"ambassador": map[string]any{"hostname": "admin-my-ns.cluster.jetpack.dev"},
+				"image":      map[string]any{"repository": "", "tag": ""},
+				"jetpack": map[string]any{
+					"clusterHostname": "cluster.jetpack.dev",
+					"cronjobs":        []any{},
+					"jobs":            []any{},
+					"projectId":       "proj_4pss8BskaTPOWzuhyY7cfL",
+				},
+				"podPort": 3000,
+				"resources": map[string]any{
+					"requests": map[string]any{"cpu": "250m", "memory": "512Mi"},
+				},
+			},
+		},
+		hvc.AdditionalAppValues(),
+	)
+}
+
+func (s *Suite) TestWorkerValues() {
+	req := s.Require()
+	hvc := s.computeValues(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+  consumer:
+    type: worker
+    command: [node, consumer.js]
+`, "my-ns", provider.KubeConfigCluster("cluster.jetpack.dev", true, "my-cluster", false), nil)
+
+	req.Equal(
+		map[string]any{"hostname": "api-my-ns.cluster.jetpack.dev"},
+		hvc.AppValues()["ambassador"],
+	)
+
+	values := hvc.AdditionalAppValues()
+	req.Len(values, 1)
+	worker := values["consumer"]
+	req.Equal(1, worker["replicaCount"])
+	req.Equal([]string{"node", "consumer.js"}, worker["command"])
+	req.Equal(map[string]any{"enabled": false}, worker["service"])
+	req.Equal(map[string]any{"enabled": false}, worker["ambassador"])
+	req.NotContains(worker, "podPort")
+	req.Equal(
+		map[string]any{"requests": map[string]any{"cpu": "250m", "memory": "512Mi"}},
+		worker["resources"],
+	)
+	req.Empty(worker["jetpack"].(map[string]any)["cronjobs"])
+}
+
+func (s *Suite) TestInternalServiceValues() {
+	req := s.Require()
+	hvc := s.computeValues(`configVersion: 0.1.2
+projectId: proj_4pss8BskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+  users:
+    type: internal
+`, "my-ns", provider.KubeConfigCluster("cluster.jetpack.dev", true, "my-cluster", false), nil)
+
+	req.Equal(
+		map[string]any{"hostname": "api-my-ns.cluster.jetpack.dev"},
+		hvc.AppValues()["ambassador"],
+	)
+
+	values := hvc.AdditionalAppValues()
+	req.Len(values, 1)
+	internal := values["users"]
+	req.Equal(jetconfig.DefaultAppPodPort, internal["podPort"])
+	req.Equal(map[string]any{"type": "ClusterIP"}, internal["service"])
+	// Internal services are not reachable from outside the cluster
+	req.Equal(map[string]any{"enabled": false}, internal["ambassador"])
+}
+
+func (s *Suite) TestServiceValues() {
+	preDeployJobs := `services:
+  api:
+    type: web
+  migrate:
+    type: job
+    command: [python, manage.py, migrate]
+    runBefore: [api]
+    backoffLimit: 0
+    activeDeadlineSeconds: 900
+  report:
+    type: job
+    command: [python, report.py]
+    parallelism: 2
+`
+	labelsAndAnnotations := `labels:
+  team: payments
+annotations:
+  example.com/owner: payments@example.com
+services:
+  api:
+    type: web
+    labels:
+      example.com/tier: frontend
+  consumer:
+    type: worker
+    image: busybox:1.36
+  cleanup:
+    type: cron
+    image: busybox:1.36
+    schedule: "@daily"
+    annotations:
+      example.com/runbook: https://wiki.example.com/cleanup
+`
+
+	cases := []struct {
+		name     string
+		yaml     string
+		release  release
+		expected map[string]any
+	}{
+		{
+			"pre-deploy release",
+			preDeployJobs,
+			preDeployRelease,
+			map[string]any{
+				"replicaCount": nil,
+				"deployment":   map[string]any{"enabled": false},
+				"service":      map[string]any{"enabled": false},
+			},
+		},
+		{
+			"pre-deploy jobs",
+			preDeployJobs,
+			jobs(preDeployRelease),
+			map[string]any{
+				"py-dockerfile-migrate": map[string]any{
+					"backoffLimit":          0,
+					"activeDeadlineSeconds": 900,
+					"parallelism":           nil,
+				},
+				"py-dockerfile-report": nil,
+			},
+		},
+		{
+			"jobs after deploy",
+			preDeployJobs,
+			jobs(appRelease),
+			map[string]any{
+				"py-dockerfile-migrate": nil,
+				"py-dockerfile-report": map[string]any{
+					"parallelism":             2,
+					"ttlSecondsAfterFinished": nil,
+				},
+			},
+		},
+		{
+			"service env and config files",
+			`services:
+  api:
+    type: web
+  consumer:
+    type: worker
+    env:
+      QUEUE: jobs
+      DATABASE_URL:
+        secret: PROD_DATABASE_URL
+    configFiles:
+      - path: consumer.toml
+        mountPath: /etc/consumer/consumer.toml
+`,
+			additionalRelease("consumer"),
+			map[string]any{
+				// secrets are set by the deploy, not in the values
+				"env": []any{map[string]any{"name": "QUEUE", "value": "jobs"}},
+				"volumeMounts": []map[string]any{{
+					"name":      "config-map-mount-py-dockerfile-consumer-config-files",
+					"mountPath": "/etc/consumer/consumer.toml",
+					"subPath":   "etc_consumer_consumer.toml",
+					"readOnly":  true,
+				}},
+			},
+		},
+		{
+			"service volumes",
+			`services:
+  api:
+    type: web
+  consumer:
+    type: worker
+    volumes:
+      - name: data
+        mountPath: /var/lib/consumer
+        size: 5Gi
+`,
+			additionalRelease("consumer"),
+			map[string]any{
+				"volumes": []map[string]any{{
+					"name": "volume-data",
+					"persistentVolumeClaim": map[string]any{
+						"claimName": "py-dockerfile-consumer-data",
+					},
+				}},
+				"volumeMounts": []map[string]any{
+					{"name": "volume-data", "mountPath": "/var/lib/consumer"},
+				},
+			},
+		},
+		{
+			"service build image",
+			`services:
+  api:
+    type: web
+    build:
+      dockerfile: services/api/Dockerfile
+`,
+			appRelease,
+			map[string]any{
+				"image": map[string]any{"repository": "registry.example.com/py-dockerfile", "tag": "b"},
+			},
+		},
+		{
+			"cronjob project image",
+			`services:
+  cleanup:
+    type: cron
+    schedule: "0 * * * *"
+    command: [cleanup]
+`,
+			cronjobs(appRelease),
+			map[string]any{
+				"py-dockerfile-cleanup": map[string]any{
+					"image": "registry.example.com/py-dockerfile:a",
+				},
+			},
+		},
+		{
+			"service image",
+			`services:
+  api:
+    type: web
+  consumer:
+    type: worker
+    image: busybox:1.36
+`,
+			additionalRelease("consumer"),
+			map[string]any{
+				"image": map[string]any{"repository": "busybox", "tag": "1.36"},
+			},
+		},
+		{
+			"cronjob schedule",
+			`services:
+  report:
+    type: cron
+    image: busybox:1.36
+    schedule: "30 9 * * MON-FRI"
+    timeZone: America/New_York
+    suspend: true
+    successfulJobsHistoryLimit: 0
+`,
+			cronjobs(appRelease),
+			map[string]any{
+				"py-dockerfile-report": map[string]any{
+					"schedule":                   "30 9 * * MON-FRI",
+					"timeZone":                   "America/New_York",
+					"suspend":                    true,
+					"successfulJobsHistoryLimit": 0,
+					"failedJobsHistoryLimit":     nil,
+					"startingDeadlineSeconds":    nil,
+				},
+			},
+		},
+		{
+			"spot scheduling",
+			`scheduling:
+  spot: true
+services:
+  api:
+    type: web
+    scheduling:
+      spot: false
+  consumer:
+    type: worker
+    image: busybox:1.36
+`,
+			additionalRelease("consumer"),
+			map[string]any{
+				"nodeSelector": map[string]string{"eks.amazonaws.com/capacityType": "SPOT"},
+				"tolerations": []map[string]any{{
+					"key":      "eks.amazonaws.com/capacityType",
+					"operator": "Equal",
+					"value":    "SPOT",
+					"effect":   "NoSchedule",
+				}},
+			},
+		},
+		{
+			"service topology spread",
+			`scheduling:
+  spot: true
+services:
+  api:
+    type: web
+    scheduling:
+      spot: false
+      topologySpread:
+        - topologyKey: topology.kubernetes.io/zone
+`,
+			appRelease,
+			map[string]any{
+				"nodeSelector": nil,
+				"tolerations":  nil,
+				"topologySpreadConstraints": []map[string]any{{
+					"maxSkew":           1,
+					"topologyKey":       "topology.kubernetes.io/zone",
+					"whenUnsatisfiable": "DoNotSchedule",
+					"labelSelector": map[string]any{
+						"matchLabels": map[string]any{"app.kubernetes.io/instance": "py-dockerfile-api"},
+					},
+				}},
+			},
+		},
+		{
+			"job topology spread",
+			`scheduling:
+  spot: true
+services:
+  resize-images:
+    type: job
+    image: busybox:1.36
+    parallelism: 4
+    scheduling:
+      topologySpread:
+        - topologyKey: kubernetes.io/hostname
+          maxSkew: 2
+`,
+			jobs(appRelease),
+			map[string]any{
+				"py-dockerfile-resize-images": map[string]any{
+					"nodeSelector": map[string]string{"eks.amazonaws.com/capacityType": "SPOT"},
+					"topologySpreadConstraints": []map[string]any{{
+						"maxSkew":           2,
+						"topologyKey":       "kubernetes.io/hostname",
+						"whenUnsatisfiable": "DoNotSchedule",
+						"labelSelector": map[string]any{
+							"matchExpressions": []any{
+								map[string]any{"key": "batch.kubernetes.io/job-name", "operator": "Exists"},
+							},
+						},
+						"matchLabelKeys": []string{"batch.kubernetes.io/job-name"},
+					}},
+				},
+			},
+		},
+		{
+			"service labels",
+			labelsAndAnnotations,
+			appRelease,
+			map[string]any{
+				"commonLabels":      map[string]string{"team": "payments"},
+				"commonAnnotations": map[string]string{"example.com/owner": "payments@example.com"},
+				"labels":            map[string]string{"team": "payments", "example.com/tier": "frontend"},
+			},
+		},
+		{
+			"cronjob annotations",
+			labelsAndAnnotations,
+			cronjobs(appRelease),
+			map[string]any{
+				"py-dockerfile-cleanup": map[string]any{
+					"labels": map[string]string{"team": "payments"},
+					"annotations": map[string]string{
+						"example.com/owner":   "payments@example.com",
+						"example.com/runbook": "https://wiki.example.com/cleanup",
+					},
+				},
+			},
+		},
+		{
+			"project labels",
+			labelsAndAnnotations,
+			additionalRelease("consumer"),
+			map[string]any{
+				"commonLabels": map[string]string{"team": "payments"},
+				"labels":       map[string]string{"team": "payments"},
+			},
+		},
+	}
+
+	// The services of every case that build an image use these
+	images := NewImageProvider(
+		"py-dockerfile:1",
+		map[string]string{"api": "py-dockerfile-api:1"},
+		map[string]string{
+			"py-dockerfile:1":     "registry.example.com/py-dockerfile:a",
+			"py-dockerfile-api:1": "registry.example.com/py-dockerfile:b",
+		},
+		"registry.example.com",
+	)
+	for _, tc := range cases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			hvc := s.computeValues(
+				projectYAML+tc.yaml,
+				"my-ns",
+				provider.KubeConfigCluster("cluster.jetpack.dev", true, "my-cluster", false),
+				images,
+			)
+			s.requireValues(tc.expected, tc.release(hvc), tc.name)
+		})
+	}
+}
+
+func (s *Suite) TestContainerValues() {
+	req := s.Require()
+
+	req.Equal(
+		map[string]any{"name": "wait", "image": "busybox:1.36"},
+		containerValues(jetconfig.Container{Name: "wait", Image: "busybox:1.36"}, 0),
+	)
+	req.Equal(
+		map[string]any{
+			"name":    "proxy",
+			"image":   "proxy:2",
+			"command": []string{"/proxy", "db"},
+			"env": []map[string]any{
+				{"name": "A", "value": "1"},
+				{"name": "B", "value": "2"},
+			},
+			"ports": []map[string]any{{"containerPort": 5432}},
+			"resources": map[string]any{
+				"limits": map[string]any{"memory": "256Mi"},
+			},
+		},
+		containerValues(jetconfig.Container{
+			Name:    "proxy",
+			Image:   "proxy:2",
+			Command: []string{"/proxy", "db"},
+			Env:     map[string]string{"B": "2", "A": "1"},
+			Ports:   []int{5432},
+			Resources: jetconfig.Resources{
+				Limits: jetconfig.ResourceList{Memory: "256Mi"},
+			},
+		}, 0),
+	)
+}
@@ -14,17 +14,23 @@ type ImageProvider struct {
 
 	// For published images, key is local image, value is published image
 	imagePublishMap map[string]string
+
+	// Images built for services with their own build, key is service name,
+	// value is local image
+	serviceLocalImages map[string]string
 }
 
 func NewImageProvider(
 	defaultLocalImage string,
+	serviceLocalImages map[string]string,
 	imagePublishMap map[string]string,
 	imageRegistryHost string,
 ) *ImageProvider {
 	return &ImageProvider{
-		defaultLocalImage: defaultLocalImage,
-		imagePublishMap:   imagePublishMap,
-		imageRegistryHost: imageRegistryHost,
+		defaultLocalImage:  defaultLocalImage,
+		imagePublishMap:    imagePublishMap,
+		imageRegistryHost:  imageRegistryHost,
+		serviceLocalImages: serviceLocalImages,
 	}
 }
 
@@ -59,11 +65,30 @@ func (i *ImageProvider) get(c provider.Cluster, img string) string {
 	return i.imagePublishMap[i.defaultLocalImage]
 }
 
+// getForService returns the image of a service: the one built for it if it
+// has its own build, else its image or the default image.
+func (i *ImageProvider) getForService(c provider.Cluster, svc builderService) string {
+	if i != nil && svc.GetImage() == "" && i.serviceLocalImages[svc.GetName()] != "" {
+		return i.get(c, i.serviceLocalImages[svc.GetName()])
+	}
+	return i.get(c, svc.GetImage())
+}
+
+func (i *ImageProvider) getSplitForService(
+	c provider.Cluster,
+	svc builderService,
+) (string, string) {
+	return splitImage(i.getForService(c, svc))
+}
+
 func (i *ImageProvider) getSplit(
 	c provider.Cluster,
 	img string,
 ) (string, string) {
-	image := i.get(c, img)
+	return splitImage(i.get(c, img))
+}
+
+func splitImage(image string) (string, string) {
 	parts := strings.Split(image, ":")
 	imageLocation := image
 	imageTag := ""
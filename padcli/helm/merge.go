@@ -34,6 +34,21 @@ func MergeValues(
 	return mergeMaps(base, v), nil
 }
 
+// MergeValuesFiles merges value files in order, like `helm install -f a.yaml
+// -f b.yaml`, and then values on top of them.
+func MergeValuesFiles(
+	valueFiles []string,
+	values map[string]any,
+) (map[string]any, error) {
+	v, err := (&valuesLib.Options{
+		ValueFiles: valueFiles,
+	}).MergeValues(getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return mergeMaps(v, values), nil
+}
+
 // Copy pasted from https://pkg.go.dev/helm.sh/helm/v3@v3.9.0/pkg/cli/values
 // Question: launchpad/deploy.go uses mergo which can merge slices but for
 // consistency with helm, maybe we should switch to this?
@@ -13,17 +13,6 @@ func SetNestedField(values map[string]any, field1 string, field2 string, value a
 	values[field1].(map[string]any)[field2] = value
 }
 
-func setNestedFieldPath(values map[string]any, fp []string, value any) {
-	if len(fp) == 0 {
-		return
-	} else if len(fp) == 1 {
-		values[fp[0]] = value
-		return
-	}
-	ensureFieldIsMap(values, fp[0])
-	setNestedFieldPath(values[fp[0]].(map[string]any), fp[1:], value)
-}
-
 func ensureFieldIsMap(values map[string]any, field string) {
 	if _, ok := values[field].(map[string]any); !ok {
 		values[field] = map[string]any{}
@@ -0,0 +1,79 @@
+package jetconfig
+
+import (
+	"path"
+
+	"github.com/samber/lo"
+)
+
+// Build is how to build the Docker image of a service, for projects where
+// services don't share the Dockerfile at the root of the project:
+//
+//	build:
+//	  context: .
+//	  dockerfile: services/api/Dockerfile
+//	  target: release
+//	  args:
+//	    GO_VERSION: "1.20"
+//
+// Paths are relative to launchpad.yaml.
+type Build struct {
+	// Context defaults to the directory of launchpad.yaml.
+	Context string `yaml:"context,omitempty"`
+	// Dockerfile defaults to the Dockerfile in the build context.
+	Dockerfile string            `yaml:"dockerfile,omitempty"`
+	Target     string            `yaml:"target,omitempty"`
+	Args       map[string]string `yaml:"args,omitempty"`
+}
+
+// GetContext returns the build context relative to launchpad.yaml.
+func (b *Build) GetContext() string {
+	return lo.Ternary(b.Context == "", ".", b.Context)
+}
+
+// GetDockerfile returns the path of the Dockerfile relative to launchpad.yaml.
+func (b *Build) GetDockerfile() string {
+	if b.Dockerfile == "" {
+		return path.Join(b.GetContext(), "Dockerfile")
+	}
+	return b.Dockerfile
+}
+
+// GetBuild returns how to build the service's image, or nil if it uses the
+// project's Dockerfile or an existing image. Args are interpolated.
+func (b *builder) GetBuild() *Build {
+	if b.Build == nil {
+		return nil
+	}
+	build := *b.Build
+	build.Args = lo.MapValues(build.Args, func(v string, _ string) string {
+		return b.cfg.interpolate(v)
+	})
+	return &build
+}
+
+func validBuildRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		b, ok := svc.(Builder)
+		if !ok || b.GetBuild() == nil {
+			continue
+		}
+		build := b.GetBuild()
+		if b.GetImage() != "" {
+			return validationError(
+				"service %s must have either an image or a build, not both",
+				svc.GetName(),
+			)
+		}
+		for _, p := range []string{build.Context, build.Dockerfile} {
+			if path.IsAbs(p) {
+				return validationError(
+					"build context and dockerfile of service %s must be relative to %s",
+					svc.GetName(),
+					defaultFileName,
+				)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package jetconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Container is an additional container in a service's pods. Sidecars run
+// alongside the service, e.g. a database proxy. Init containers run to
+// completion, one after the other, before the service starts, e.g. to wait for
+// a dependency:
+//
+//	sidecars:
+//	  - name: cloud-sql-proxy
+//	    image: gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.1.0
+//	    command: [/cloud-sql-proxy, my-project:us-central1:db]
+//	    ports: [5432]
+//	initContainers:
+//	  - name: wait-for-redis
+//	    image: busybox:1.36
+//	    command: [sh, -c, "until nc -z redis 6379; do sleep 1; done"]
+type Container struct {
+	Name    string            `yaml:"name"`
+	Image   string            `yaml:"image"`
+	Command []string          `yaml:"command,omitempty,flow"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Ports   []int             `yaml:"ports,omitempty,flow"`
+	// Resources are only what's set explicitly. Instance types don't apply to
+	// additional containers.
+	Resources Resources `yaml:"resources,omitempty"`
+}
+
+func (b *builder) GetSidecars() []Container {
+	return b.interpolateContainers(b.Sidecars)
+}
+
+func (b *builder) GetInitContainers() []Container {
+	return b.interpolateContainers(b.InitContainers)
+}
+
+func (b *builder) interpolateContainers(containers []Container) []Container {
+	return lo.Map(containers, func(c Container, _ int) Container {
+		c.Image = b.cfg.interpolate(c.Image)
+		c.Command = lo.Map(c.Command, func(arg string, _ int) string {
+			return b.cfg.interpolate(arg)
+		})
+		c.Env = lo.MapValues(c.Env, func(v string, _ string) string {
+			return b.cfg.interpolate(v)
+		})
+		return c
+	})
+}
+
+// containerInterpolatedFields returns the fields of additional containers that
+// support variables, keyed by e.g. sidecars.proxy.image.
+func (b *builder) containerInterpolatedFields() map[string]string {
+	fields := map[string]string{}
+	for kind, containers := range map[string][]Container{
+		"sidecars":       b.Sidecars,
+		"initContainers": b.InitContainers,
+	} {
+		for _, c := range containers {
+			prefix := fmt.Sprintf("%s.%s.", kind, c.Name)
+			fields[prefix+"image"] = c.Image
+			fields[prefix+"command"] = strings.Join(c.Command, " ")
+			for name, v := range c.Env {
+				fields[prefix+"env."+name] = v
+			}
+		}
+	}
+	return fields
+}
+
+var containerNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func validContainersRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		b, ok := svc.(Builder)
+		if !ok {
+			continue
+		}
+		switch svc.(type) {
+		case *job, *cron:
+			// A job isn't complete until all of its containers exit, and sidecars
+			// usually don't.
+			if len(b.GetSidecars()) > 0 {
+				return validationError(
+					"service %s runs to completion and can't have sidecars. "+
+						"Use initContainers instead",
+					svc.GetName(),
+				)
+			}
+		}
+		names := map[string]bool{}
+		for _, c := range append(b.GetInitContainers(), b.GetSidecars()...) {
+			if !containerNamePattern.MatchString(c.Name) || len(c.Name) > 63 {
+				return validationError(
+					"container name %q of service %s must consist of lower case letters, "+
+						"numbers and '-'",
+					c.Name,
+					svc.GetName(),
+				)
+			}
+			if names[c.Name] {
+				return validationError(
+					"container %s of service %s is declared more than once",
+					c.Name,
+					svc.GetName(),
+				)
+			}
+			names[c.Name] = true
+			if c.Image == "" {
+				return validationError(
+					"container %s of service %s must have an image",
+					c.Name,
+					svc.GetName(),
+				)
+			}
+			envNames := maps.Keys(c.Env)
+			slices.Sort(envNames)
+			for _, name := range envNames {
+				if !envVarNamePattern.MatchString(name) {
+					return validationError(
+						"env %s of container %s of service %s is not a valid environment "+
+							"variable name",
+						name,
+						c.Name,
+						svc.GetName(),
+					)
+				}
+			}
+			for _, port := range c.Ports {
+				if port < 1 || port > 65535 {
+					return validationError(
+						"port %d of container %s of service %s must be between 1 and 65535",
+						port,
+						c.Name,
+						svc.GetName(),
+					)
+				}
+			}
+			if !validContainerResources(c.Resources) {
+				return validationError(
+					"resources of container %s of service %s must be valid quantities, "+
+						"e.g. 500m or 1Gi, with requests no greater than limits",
+					c.Name,
+					svc.GetName(),
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func validContainerResources(r Resources) bool {
+	for _, q := range [][2]Quantity{
+		{r.Requests.CPU, r.Limits.CPU},
+		{r.Requests.Memory, r.Limits.Memory},
+	} {
+		quantities := []resource.Quantity{}
+		for _, s := range q {
+			if s == "" {
+				continue
+			}
+			quantity, err := s.parse()
+			if err != nil {
+				return false
+			}
+			quantities = append(quantities, quantity)
+		}
+		if len(quantities) == 2 && quantities[0].Cmp(quantities[1]) > 0 {
+			return false
+		}
+	}
+	return true
+}
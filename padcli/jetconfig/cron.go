@@ -1,5 +1,16 @@
 package jetconfig
 
+import (
+	"strings"
+	"time"
+	// Time zones are validated without relying on the system's tz database
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/pkg/cronschedule"
+)
+
 // Public Cron interface
 type Cron interface {
 	Builder
@@ -7,6 +18,11 @@ type Cron interface {
 	GetSchedule() string
 	GetConcurrencyPolicy() string
 	GetCommand() []string
+	GetTimeZone() string
+	IsSuspended() bool
+	GetStartingDeadlineSeconds() *int
+	GetSuccessfulJobsHistoryLimit() *int
+	GetFailedJobsHistoryLimit() *int
 }
 
 // instantiates a new Cron service for initcmd
@@ -16,17 +32,17 @@ func (c *Config) AddNewCronService(
 	schedule string,
 ) Cron {
 	newCron := &cron{
-		Command:  command,
-		Schedule: schedule,
+		Command:  newEnvDependentField(command),
+		Schedule: newEnvDependentField(schedule),
 		builder: builder{
-			Image: "busybox:latest",
+			Image: newEnvDependentField("busybox:latest"),
 		},
 		service: service{
-			parent: c,
-			name:   name,
-			Type:   CronType,
+			name: name,
+			Type: CronType,
 		},
 	}
+	newCron.setParent(c)
 	c.Services = append(c.Services, newCron)
 	return newCron
 }
@@ -35,24 +51,121 @@ func (c *Config) AddNewCronService(
 type cron struct {
 	service          `yaml:",inline,omitempty"`
 	builder          `yaml:",inline,omitempty"`
-	Command          []string `yaml:"command,omitempty,flow"`
-	Schedule         string   `yaml:"schedule,omitempty"`
-	ConcurrentPolicy string   `yaml:"concurrencyPolicy,omitempty"`
+	Command          envDependentField[[]string] `yaml:"command,omitempty,flow"`
+	Schedule         envDependentField[string]   `yaml:"schedule,omitempty"`
+	ConcurrentPolicy envDependentField[string]   `yaml:"concurrencyPolicy,omitempty"`
+	// TimeZone is an IANA time zone such as America/New_York. It defaults to
+	// the time zone of the cluster's controller manager, usually UTC.
+	TimeZone envDependentField[string] `yaml:"timeZone,omitempty"`
+	Suspend  envDependentField[bool]   `yaml:"suspend,omitempty"`
+	// Unset fields below use the kubernetes defaults.
+	StartingDeadlineSeconds    envDependentField[int] `yaml:"startingDeadlineSeconds,omitempty"`
+	SuccessfulJobsHistoryLimit envDependentField[int] `yaml:"successfulJobsHistoryLimit,omitempty"`
+	FailedJobsHistoryLimit     envDependentField[int] `yaml:"failedJobsHistoryLimit,omitempty"`
 }
 
 var _ Cron = (*cron)(nil)
 
+func (c *cron) setParent(p *Config) {
+	c.service.setParent(p)
+	c.builder.setParent(p)
+}
+
 func (c *cron) GetSchedule() string {
-	return c.Schedule
+	return c.Schedule.Get(c.parent.env())
 }
 
 func (c *cron) GetConcurrencyPolicy() string {
-	if c.ConcurrentPolicy == "" {
+	policy := c.ConcurrentPolicy.Get(c.parent.env())
+	if policy == "" {
 		return "Allow" // k8s default
 	}
-	return c.ConcurrentPolicy
+	return policy
+}
+
+func (c *cron) GetTimeZone() string {
+	return c.TimeZone.Get(c.parent.env())
+}
+
+func (c *cron) IsSuspended() bool {
+	return c.Suspend.Get(c.parent.env())
+}
+
+func (c *cron) GetStartingDeadlineSeconds() *int {
+	return c.StartingDeadlineSeconds.LookupPtr(c.parent.env())
+}
+
+func (c *cron) GetSuccessfulJobsHistoryLimit() *int {
+	return c.SuccessfulJobsHistoryLimit.LookupPtr(c.parent.env())
+}
+
+func (c *cron) GetFailedJobsHistoryLimit() *int {
+	return c.FailedJobsHistoryLimit.LookupPtr(c.parent.env())
+}
+
+// NextRuns returns the next n times after t that the cronjob runs, in its
+// time zone.
+func NextRuns(c Cron, t time.Time, n int) ([]time.Time, error) {
+	schedule, err := cronschedule.Parse(c.GetSchedule())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	loc, err := time.LoadLocation(c.GetTimeZone())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return schedule.NextN(t.In(loc), n), nil
+}
+
+func validCronsRule(cfg *Config) error {
+	for _, c := range cfg.Cronjobs() {
+		if _, err := cronschedule.Parse(c.GetSchedule()); err != nil {
+			return validationError(
+				"schedule %q of cronjob %s is not valid: %s",
+				c.GetSchedule(),
+				c.GetName(),
+				err,
+			)
+		}
+		if tz := c.GetTimeZone(); tz != "" {
+			// time.LoadLocation treats "" as UTC and "Local" as the local time zone
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+				return validationError(
+					"timeZone %s of cronjob %s is not a known time zone, such as "+
+						"America/New_York",
+					tz,
+					c.GetName(),
+				)
+			}
+		}
+		for _, f := range []struct {
+			name  string
+			value *int
+		}{
+			{"startingDeadlineSeconds", c.GetStartingDeadlineSeconds()},
+			{"successfulJobsHistoryLimit", c.GetSuccessfulJobsHistoryLimit()},
+			{"failedJobsHistoryLimit", c.GetFailedJobsHistoryLimit()},
+		} {
+			if f.value != nil && *f.value < 0 {
+				return validationError(
+					"%s of cronjob %s must not be negative",
+					f.name,
+					c.GetName(),
+				)
+			}
+		}
+	}
+	return nil
 }
 
 func (c *cron) GetCommand() []string {
-	return c.Command
+	return lo.Map(c.Command.Get(c.parent.env()), func(arg string, _ int) string {
+		return c.parent.interpolate(arg)
+	})
+}
+
+func (c *cron) interpolatedFields() map[string]string {
+	return lo.Assign(c.builder.interpolatedFields(), map[string]string{
+		"command": strings.Join(c.Command.Get(c.parent.env()), " "),
+	})
 }
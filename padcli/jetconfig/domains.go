@@ -0,0 +1,130 @@
+package jetconfig
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// IngressFields configure how web services with custom domains are reached
+// from outside the cluster, on any cluster:
+//
+//	ingress:
+//	  acmeEmail: ops@example.com
+//	services:
+//	  web:
+//	    type: web
+//	    domains: [example.com, www.example.com]
+//
+// launchpad gets a Let's Encrypt certificate for the domains from cert-manager,
+// which must be installed in the cluster.
+type IngressFields struct {
+	// Controller is the cluster's ingress controller: ingress, for Kubernetes
+	// Ingresses, or ambassador. It defaults to ambassador on Jetpack-managed
+	// clusters and to ingress otherwise.
+	Controller string `yaml:"controller,omitempty"`
+	// ClassName is the ingress class, e.g. nginx. Empty means the cluster's
+	// default class.
+	ClassName string `yaml:"className,omitempty"`
+	// ACMEEmail is who Let's Encrypt notifies about certificates that are about
+	// to expire.
+	ACMEEmail string `yaml:"acmeEmail,omitempty"`
+	// ClusterIssuer is an existing cert-manager ClusterIssuer to get the
+	// certificates from, instead of an issuer that launchpad creates.
+	ClusterIssuer string `yaml:"clusterIssuer,omitempty"`
+}
+
+const (
+	IngressControllerIngress    = "ingress"
+	IngressControllerAmbassador = "ambassador"
+)
+
+// GetDomains returns the custom domains of the web service. Values support
+// variables.
+func (w *web) GetDomains() []string {
+	if w == nil {
+		return nil
+	}
+	return lo.Map(w.Domains.Get(w.parent.env()), func(d string, _ int) string {
+		return w.parent.interpolate(d)
+	})
+}
+
+func (w *web) domainsInterpolatedFields() map[string]string {
+	fields := map[string]string{}
+	for i, d := range w.Domains.Get(w.parent.env()) {
+		fields[fmt.Sprintf("domains[%d]", i)] = d
+	}
+	return fields
+}
+
+func validDomainsRule(cfg *Config) error {
+	owners := map[string]string{}
+	for _, w := range cfg.WebServices() {
+		for _, d := range w.GetDomains() {
+			if strings.HasPrefix(d, "*.") {
+				return validationError(
+					"domain %s of service %s can't be a wildcard, because certificates "+
+						"for wildcards can't be issued over HTTP",
+					d,
+					w.GetName(),
+				)
+			}
+			if len(validation.IsFullyQualifiedDomainName(field.NewPath("domains"), d)) > 0 {
+				return validationError(
+					"domain %s of service %s must be a domain name, such as example.com",
+					d,
+					w.GetName(),
+				)
+			}
+			if owner, ok := owners[d]; ok {
+				return validationError(
+					"domain %s is used by services %s and %s",
+					d,
+					owner,
+					w.GetName(),
+				)
+			}
+			owners[d] = w.GetName()
+		}
+	}
+	return nil
+}
+
+func validIngressRule(cfg *Config) error {
+	switch cfg.Ingress.Controller {
+	case "", IngressControllerIngress, IngressControllerAmbassador:
+	default:
+		return validationError(
+			"ingress controller %s should be one of %s, %s",
+			cfg.Ingress.Controller,
+			IngressControllerIngress,
+			IngressControllerAmbassador,
+		)
+	}
+
+	hasDomains := lo.SomeBy(cfg.WebServices(), func(w Web) bool {
+		return len(w.GetDomains()) > 0
+	})
+	if !hasDomains || cfg.Ingress.ClusterIssuer != "" {
+		return nil
+	}
+	if cfg.Ingress.ACMEEmail == "" {
+		return validationError(
+			"ingress.acmeEmail must be set, so that Let's Encrypt can issue " +
+				"certificates for the domains of web services, unless " +
+				"ingress.clusterIssuer is set",
+		)
+	}
+	if _, err := mail.ParseAddress(cfg.Ingress.ACMEEmail); err != nil {
+		return validationError(
+			"ingress.acmeEmail %s is not a valid email address",
+			cfg.Ingress.ACMEEmail,
+		)
+	}
+	return nil
+}
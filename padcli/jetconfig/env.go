@@ -0,0 +1,154 @@
+package jetconfig
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is the value of an environment variable of a service. A plain value
+// is set as is. A mapping with secret refers to a key in the project's secrets
+// (see `launchpad env`), so the value itself stays out of version control:
+//
+//	env:
+//	  LOG_LEVEL: info
+//	  API_URL:
+//	    dev: https://api.dev.example.com
+//	    prod: https://api.example.com
+//	  DATABASE_URL:
+//	    secret: PROD_DATABASE_URL
+type EnvVar struct {
+	Value  string `yaml:"value,omitempty"`
+	Secret string `yaml:"secret,omitempty"`
+}
+
+// plainEnvVar has the fields of EnvVar without its yaml methods.
+type plainEnvVar EnvVar
+
+func (v *EnvVar) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*v = EnvVar{Value: value.Value}
+		return nil
+	}
+	return errors.WithStack(value.Decode((*plainEnvVar)(v)))
+}
+
+func (v EnvVar) MarshalYAML() (any, error) {
+	if v.Secret == "" {
+		return v.Value, nil
+	}
+	return plainEnvVar(v), nil
+}
+
+func (EnvVar) jsonSchema() *jsonSchema {
+	return &jsonSchema{OneOf: []*jsonSchema{
+		{Type: "string"},
+		{Type: "number"},
+		{Type: "boolean"},
+		schemaForType(reflect.TypeOf(plainEnvVar{})),
+	}}
+}
+
+// ConfigFile is a file in the repository, relative to launchpad.yaml, that is
+// mounted read-only into the service's containers at MountPath. The files of a
+// service are stored in a ConfigMap, so they are limited to 1MiB in total:
+//
+//	configFiles:
+//	  - path: config/nginx.conf
+//	    mountPath: /etc/nginx/nginx.conf
+type ConfigFile struct {
+	Path      string `yaml:"path"`
+	MountPath string `yaml:"mountPath"`
+}
+
+var nonConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+
+// Key returns the ConfigMap key that stores the file. It's derived from the
+// mount path, which is unique within a service.
+func (f ConfigFile) Key() string {
+	return nonConfigMapKeyChars.ReplaceAllString(strings.Trim(f.MountPath, "/"), "_")
+}
+
+// GetEnv returns the environment variables of the service for the selected
+// environment. Plain values are interpolated.
+func (b *builder) GetEnv() map[string]EnvVar {
+	env := map[string]EnvVar{}
+	for name, field := range b.Env {
+		v, ok := field.Lookup(b.cfg.env())
+		if !ok {
+			continue
+		}
+		v.Value = b.cfg.interpolate(v.Value)
+		env[name] = v
+	}
+	return env
+}
+
+func (b *builder) GetConfigFiles() []ConfigFile {
+	return b.ConfigFiles
+}
+
+// envVarNamePattern is the pattern used by shells, which is stricter than what
+// kubernetes allows.
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validEnvRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		b, ok := svc.(Builder)
+		if !ok {
+			continue
+		}
+		env := b.GetEnv()
+		names := maps.Keys(env)
+		slices.Sort(names)
+		for _, name := range names {
+			if !envVarNamePattern.MatchString(name) {
+				return validationError(
+					"env %s of service %s is not a valid environment variable name",
+					name,
+					svc.GetName(),
+				)
+			}
+			if env[name].Value != "" && env[name].Secret != "" {
+				return validationError(
+					"env %s of service %s must have either a value or a secret, not both",
+					name,
+					svc.GetName(),
+				)
+			}
+		}
+
+		mountPaths := map[string]bool{}
+		for _, f := range b.GetConfigFiles() {
+			if f.Path == "" || path.IsAbs(f.Path) {
+				return validationError(
+					"configFiles of service %s must have a path relative to %s",
+					svc.GetName(),
+					defaultFileName,
+				)
+			}
+			if !path.IsAbs(f.MountPath) {
+				return validationError(
+					"configFiles mountPath %q of service %s must be an absolute path",
+					f.MountPath,
+					svc.GetName(),
+				)
+			}
+			if mountPaths[f.Key()] {
+				return validationError(
+					"configFiles mountPath %s of service %s is used more than once",
+					f.MountPath,
+					svc.GetName(),
+				)
+			}
+			mountPaths[f.Key()] = true
+		}
+	}
+	return nil
+}
@@ -8,56 +8,133 @@ import (
 	"github.com/samber/lo"
 	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/proto/api"
+	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 )
 
-type envDependentField[T any] map[api.Environment]T
+// envDependentField is a field that can either be a single value, or a map
+// from environment to value:
+//
+//	schedule: "0 * * * *"
+//
+//	schedule:
+//	  dev: "0 * * * *"
+//	  prod: "* * * * *"
+//
+// A single value is stored under allEnvironments. Keys may be any environment
+// name; validation checks that they are built-in or declared in the config.
+type envDependentField[T any] map[string]T
+
+// allEnvironments is the key of the value that applies to every environment
+const allEnvironments = ""
+
+func newEnvDependentField[T any](value T) envDependentField[T] {
+	return envDependentField[T]{allEnvironments: value}
+}
 
 func (e *envDependentField[T]) UnmarshalYAML(value *yaml.Node) error {
-	if value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
-		*e = envDependentField[T]{
-			api.Environment_NONE: reflect.ValueOf(value.Value).Interface().(T),
+	if !e.isPerEnvironment(value) {
+		var v T
+		if err := value.Decode(&v); err != nil {
+			return errors.WithStack(err)
 		}
+		*e = newEnvDependentField(v)
 		return nil
 	}
-	if value.Kind != yaml.MappingNode {
-		return errors.New("invalid yaml definition")
-	}
+
 	*e = envDependentField[T]{}
 	for _, pair := range lo.Chunk(value.Content, 2) {
-		if len(pair) != 2 ||
-			pair[0].Kind != yaml.ScalarNode ||
-			pair[1].Kind != yaml.ScalarNode {
+		if len(pair) != 2 || pair[0].Kind != yaml.ScalarNode {
 			return errors.WithStack(errors.New("invalid service definition"))
 		}
-		if !api.IsValidEnvironment(pair[0].Value) {
-			return errorutil.NewUserErrorf(
-				"invalid environment: %s. Valid options are %v",
-				pair[0].Value,
-				api.ValidLowercaseEnvironments(),
-			)
+		env := strings.ToLower(pair[0].Value)
+		if !IsValidEnvironmentName(env) {
+			return errorutil.NewUserErrorf("invalid environment name: %s", pair[0].Value)
+		}
+		var v T
+		if err := pair[1].Decode(&v); err != nil {
+			return errors.WithStack(err)
 		}
-		env := api.EnvironmentFromLowercaseString(pair[0].Value)
-		(*e)[env] = reflect.ValueOf(pair[1].Value).Interface().(T)
+		(*e)[env] = v
 	}
 	return nil
 }
 
-func (e envDependentField[T]) MarshalYAML() (any, error) {
-	if _, ok := e[api.Environment_NONE]; ok {
-		return e[api.Environment_NONE], nil
+// isPerEnvironment returns true if the node is a map from environment to
+// value. If T is a struct, the node is per-environment if none of its keys are
+// fields of T, since the environments that the config declares aren't known
+// while decoding. If T is a map, all keys must be built-in environments.
+func (e *envDependentField[T]) isPerEnvironment(value *yaml.Node) bool {
+	if value.Kind != yaml.MappingNode {
+		return false
+	}
+	keys := lo.Map(lo.Chunk(value.Content, 2), func(pair []*yaml.Node, _ int) string {
+		return pair[0].Value
+	})
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := yamlFields(t)
+		return len(keys) > 0 && lo.NoneBy(keys, func(key string) bool {
+			_, ok := fields[key]
+			return ok
+		})
+	case reflect.Map:
+		return len(keys) > 0 && lo.EveryBy(keys, api.IsValidEnvironment)
+	default:
+		return true
+	}
+}
+
+// environmentNames returns the names of the environments declared in node, a
+// config document or its root mapping.
+func environmentNames(node *yaml.Node) []string {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
 	}
+	envs := findNode(node, []string{"environment"})
+	if envs == nil || envs.Kind != yaml.MappingNode {
+		return nil
+	}
+	names := []string{}
+	for _, pair := range lo.Chunk(envs.Content, 2) {
+		names = append(names, pair[0].Value)
+	}
+	return names
+}
 
-	m := map[string]T{}
-	for env, value := range e {
-		m[strings.ToLower(env.String())] = value
+func (e envDependentField[T]) MarshalYAML() (any, error) {
+	if v, ok := e[allEnvironments]; ok {
+		return v, nil
 	}
-	return m, nil
+	return map[string]T(e), nil
+}
+
+func (e envDependentField[T]) Get(env string) T {
+	v, _ := e.Lookup(env)
+	return v
 }
 
-func (e envDependentField[T]) Get(env api.Environment) T {
+// Lookup returns the value for env, falling back to the value that applies to
+// all environments. ok is false if neither is set.
+func (e envDependentField[T]) Lookup(env string) (T, bool) {
 	if v, ok := e[env]; ok {
-		return v
+		return v, true
 	}
-	return e[api.Environment_NONE]
+	v, ok := e[allEnvironments]
+	return v, ok
+}
+
+// LookupPtr is like Lookup, but returns nil if the field is not set, e.g. so
+// that 0 can be told apart from a kubernetes default.
+func (e envDependentField[T]) LookupPtr(env string) *T {
+	if v, ok := e.Lookup(env); ok {
+		return &v
+	}
+	return nil
+}
+
+// environments returns the environments that have their own value.
+func (e envDependentField[T]) environments() []string {
+	return lo.Without(maps.Keys(e), allEnvironments)
 }
@@ -0,0 +1,158 @@
+package jetconfig
+
+import (
+	"bytes"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/pkg/kubevalidate"
+	"go.jetpack.io/launchpad/proto/api"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultJobRetention    = 24 * time.Hour
+	defaultDevJobRetention = 10 * time.Minute
+)
+
+// EnvironmentFields are the settings of an environment. The built-in
+// environments (dev, staging and prod) can be customized, and any other
+// environment (e.g. qa, perf or a per-developer environment) can be declared:
+//
+//	environment:
+//	  qa:
+//	    tagPrefix: qa-
+//	    namespace: "{{.Project}}-qa"
+//	    jobRetention: 1h
+type EnvironmentFields struct {
+	// Default flags
+	Flags FlagSet `yaml:"flags,omitempty"`
+
+	// Prefix of the image tags built for this environment. Defaults to
+	// "<environment>-".
+	TagPrefix string `yaml:"tagPrefix,omitempty"`
+
+	// Namespace to deploy to, unless --namespace is set. It's a go template that
+	// can use {{.Environment}}, {{.Project}} and {{.User}} (the local username),
+	// e.g. "{{.Project}}-{{.User}}" for per-developer environments.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// How long finished jobs and cronjob runs are kept, e.g. 10m or 24h.
+	// Defaults to 10m in dev and 24h in other environments.
+	JobRetention string `yaml:"jobRetention,omitempty"`
+}
+
+// IsValidEnvironmentName returns true if name can be used as an environment
+// name. It doesn't check whether the environment has been declared.
+func IsValidEnvironmentName(name string) bool {
+	return kubevalidate.IsValidRFC1123Name(name)
+}
+
+func isBuiltinEnvironment(name string) bool {
+	return api.IsValidEnvironment(name)
+}
+
+// Environments returns the names of the built-in environments and of the
+// environments declared in the config, sorted.
+func (c *Config) Environments() []string {
+	envs := lo.Uniq(append(
+		api.ValidLowercaseEnvironments(),
+		maps.Keys(c.Environment)...,
+	))
+	slices.Sort(envs)
+	return envs
+}
+
+// HasEnvironment returns true if env is a built-in environment or is declared
+// in the config.
+func (c *Config) HasEnvironment(env string) bool {
+	return lo.Contains(c.Environments(), env)
+}
+
+// SelectedEnvironment returns the name of the environment that the config was
+// loaded for.
+func (c *Config) SelectedEnvironment() string {
+	return c.env()
+}
+
+// SelectedEnvironmentFields returns the settings of the selected environment.
+func (c *Config) SelectedEnvironmentFields() EnvironmentFields {
+	if c == nil {
+		return EnvironmentFields{}
+	}
+	return c.Environment[c.env()]
+}
+
+// ImageTagPrefix returns the prefix of the image tags built for the selected
+// environment.
+func (c *Config) ImageTagPrefix() string {
+	if prefix := c.SelectedEnvironmentFields().TagPrefix; prefix != "" {
+		return prefix
+	}
+	if isBuiltinEnvironment(c.env()) {
+		return api.EnvironmentFromLowercaseString(c.env()).ImageTagPrefix()
+	}
+	return c.env() + "-"
+}
+
+// Namespace returns the namespace configured for the selected environment, or
+// an empty string if none is configured.
+func (c *Config) Namespace() (string, error) {
+	tmpl := c.SelectedEnvironmentFields().Namespace
+	if tmpl == "" {
+		return "", nil
+	}
+
+	t, err := template.New("namespace").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errorutil.NewUserErrorf(
+			"invalid namespace template %q for environment %s: %v",
+			tmpl,
+			c.env(),
+			err,
+		)
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, map[string]string{
+		"Environment": c.env(),
+		"Project":     c.GetProjectName(),
+		"User":        localUsername(),
+	})
+	if err != nil {
+		return "", errorutil.NewUserErrorf(
+			"invalid namespace template %q for environment %s: %v",
+			tmpl,
+			c.env(),
+			err,
+		)
+	}
+
+	ns, err := kubevalidate.ToValidName(buf.String())
+	return ns, errors.Wrapf(err, "invalid namespace %q", buf.String())
+}
+
+// JobRetention returns how long finished jobs and cronjob runs are kept in the
+// selected environment.
+func (c *Config) JobRetention() time.Duration {
+	if d, err := time.ParseDuration(c.SelectedEnvironmentFields().JobRetention); err == nil {
+		return d
+	}
+	if c.env() == api.Environment_DEV.ToLower() {
+		return defaultDevJobRetention
+	}
+	return defaultJobRetention
+}
+
+func localUsername() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return strings.ToLower(u.Username)
+	}
+	return strings.ToLower(os.Getenv("USER"))
+}
@@ -0,0 +1,121 @@
+package jetconfig
+
+import (
+	"strings"
+)
+
+// HealthCheck tells Kubernetes how to check that a service is healthy. It's
+// used for both the readiness and liveness probes, so pods only receive traffic
+// (and deploys only finish) once the check passes, and are restarted when it
+// keeps failing. Liveness allows twice FailureThreshold failures, and waits at
+// least 30 seconds unless StartupSeconds is set. Exactly one of HTTP, TCP or
+// Exec must be set:
+//
+//	healthCheck:
+//	  http:
+//	    path: /healthz
+//	  initialDelaySeconds: 5
+//	  startupSeconds: 120
+type HealthCheck struct {
+	HTTP *HTTPHealthCheck `yaml:"http,omitempty"`
+	TCP  *TCPHealthCheck  `yaml:"tcp,omitempty"`
+	// Exec is a command run in the container. Exit code 0 is healthy.
+	Exec []string `yaml:"exec,omitempty,flow"`
+
+	InitialDelaySeconds int `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int `yaml:"failureThreshold,omitempty"`
+	// StartupSeconds is how long the service may take to start. If set, a
+	// startup probe runs the check until it passes, and liveness checks only
+	// begin after that. Useful for services with slow or unpredictable starts.
+	StartupSeconds int `yaml:"startupSeconds,omitempty"`
+}
+
+// HTTPHealthCheck is healthy if a GET request to path returns a 2xx or 3xx
+// status. Port defaults to the service port.
+type HTTPHealthCheck struct {
+	Path string `yaml:"path,omitempty"`
+	Port int    `yaml:"port,omitempty"`
+}
+
+// TCPHealthCheck is healthy if a TCP connection to port can be opened. Port
+// defaults to the service port.
+type TCPHealthCheck struct {
+	Port int `yaml:"port,omitempty"`
+}
+
+// healthChecker is embedded by services that can have a health check.
+type healthChecker struct {
+	cfg         *Config
+	HealthCheck envDependentField[HealthCheck] `yaml:"healthCheck,omitempty"`
+}
+
+func (h *healthChecker) setParent(p *Config) {
+	h.cfg = p
+}
+
+// GetHealthCheck returns the health check for the selected environment, or
+// nil if the service doesn't have one.
+func (h *healthChecker) GetHealthCheck() *HealthCheck {
+	if hc, ok := h.HealthCheck.Lookup(h.cfg.env()); ok {
+		return &hc
+	}
+	return nil
+}
+
+func validHealthChecksRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		s, ok := svc.(interface{ GetHealthCheck() *HealthCheck })
+		if !ok || s.GetHealthCheck() == nil {
+			continue
+		}
+		hc := s.GetHealthCheck()
+		_, hasPort := svc.(interface{ GetPort() int })
+
+		checks := []string{}
+		if hc.HTTP != nil {
+			checks = append(checks, "http")
+			if hc.HTTP.Path != "" && !strings.HasPrefix(hc.HTTP.Path, "/") {
+				return validationError(
+					"healthCheck path %s of service %s must start with /",
+					hc.HTTP.Path,
+					svc.GetName(),
+				)
+			}
+			if hc.HTTP.Port == 0 && !hasPort {
+				return validationError(
+					"healthCheck http of service %s needs a port",
+					svc.GetName(),
+				)
+			}
+		}
+		if hc.TCP != nil {
+			checks = append(checks, "tcp")
+			if hc.TCP.Port == 0 && !hasPort {
+				return validationError(
+					"healthCheck tcp of service %s needs a port",
+					svc.GetName(),
+				)
+			}
+		}
+		if len(hc.Exec) > 0 {
+			checks = append(checks, "exec")
+		}
+		if len(checks) != 1 {
+			return validationError(
+				"healthCheck of service %s must have exactly one of http, tcp or exec",
+				svc.GetName(),
+			)
+		}
+
+		if hc.InitialDelaySeconds < 0 || hc.PeriodSeconds < 0 || hc.TimeoutSeconds < 0 ||
+			hc.FailureThreshold < 0 || hc.StartupSeconds < 0 {
+			return validationError(
+				"healthCheck durations and thresholds of service %s must not be negative",
+				svc.GetName(),
+			)
+		}
+	}
+	return nil
+}
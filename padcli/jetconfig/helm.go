@@ -1,36 +1,80 @@
 package jetconfig
 
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
 // Public HelmChart interface
 type HelmChart interface {
 	Service
 	GetRepo() string
 	GetChart() string
+	GetVersion() string
+	GetValues() map[string]any
+	GetValuesFiles() []string
+	GetNamespace() string
+	GetReleaseName() string
+	GetWait() bool
+	GetTimeout() time.Duration
+	IsOCI() bool
 }
 
-// instantiates a new Job service for initcmd
-func (c *Config) AddNewJobHelmChart(
-	name string,
-	command []string,
-	schedule string,
-) HelmChart {
+// instantiates a new HelmChart service for initcmd
+func (c *Config) AddNewHelmChart(name string, repo string, chart string) HelmChart {
 	newHelmChart := &helmChart{
+		Repo:  repo,
+		Chart: chart,
 		service: service{
-			parent: c,
-			name:   name,
-			Type:   HelmChartType,
+			name: name,
+			Type: HelmChartType,
 		},
 	}
+	newHelmChart.setParent(c)
 	c.Services = append(c.Services, newHelmChart)
 	return newHelmChart
 }
 
-// Private helmChart struct
+// Private helmChart struct. A chart is either found in a repo:
+//
+//	redis:
+//	  type: helm
+//	  repo: https://charts.bitnami.com/bitnami
+//	  chart: redis
+//	  version: 17.11.3
+//
+// or in an OCI registry, in which case repo is empty:
+//
+//	redis:
+//	  type: helm
+//	  chart: oci://registry-1.docker.io/bitnamicharts/redis
 type helmChart struct {
 	service `yaml:",inline,omitempty"`
 	Repo    string `yaml:"repo,omitempty"`
 	Chart   string `yaml:"chart,omitempty"`
+	// Version defaults to the latest version of the chart.
+	Version string         `yaml:"version,omitempty"`
+	Values  map[string]any `yaml:"values,omitempty"`
+	// ValuesFiles are relative to launchpad.yaml. Values in later files take
+	// precedence, and values take precedence over all of them.
+	ValuesFiles []string `yaml:"valuesFiles,omitempty"`
+	// Namespace defaults to the project's namespace.
+	Namespace string `yaml:"namespace,omitempty"`
+	// ReleaseName defaults to the service name followed by the project slug.
+	ReleaseName string `yaml:"releaseName,omitempty"`
+	// Wait for the chart's resources to be ready before the deploy continues.
+	Wait bool `yaml:"wait,omitempty"`
+	// Timeout is a duration such as 5m. It defaults to 5 minutes.
+	Timeout string `yaml:"timeout,omitempty"`
 }
 
+const defaultHelmChartTimeout = 5 * time.Minute
+
 func (h *helmChart) GetRepo() string {
 	return h.Repo
 }
@@ -39,6 +83,57 @@ func (h *helmChart) GetChart() string {
 	return h.Chart
 }
 
+func (h *helmChart) GetVersion() string {
+	return h.parent.interpolate(h.Version)
+}
+
+func (h *helmChart) GetValues() map[string]any {
+	return h.Values
+}
+
+func (h *helmChart) GetValuesFiles() []string {
+	return lo.Map(h.ValuesFiles, func(f string, _ int) string {
+		return h.parent.interpolate(f)
+	})
+}
+
+func (h *helmChart) GetNamespace() string {
+	return h.parent.interpolate(h.Namespace)
+}
+
+func (h *helmChart) GetReleaseName() string {
+	return h.parent.interpolate(h.ReleaseName)
+}
+
+func (h *helmChart) GetWait() bool {
+	return h.Wait
+}
+
+func (h *helmChart) GetTimeout() time.Duration {
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil || timeout == 0 {
+		return defaultHelmChartTimeout
+	}
+	return timeout
+}
+
+// IsOCI returns true if the chart is a reference to an OCI registry.
+func (h *helmChart) IsOCI() bool {
+	return strings.HasPrefix(h.Chart, "oci://")
+}
+
+func (h *helmChart) interpolatedFields() map[string]string {
+	fields := map[string]string{
+		"version":     h.Version,
+		"namespace":   h.Namespace,
+		"releaseName": h.ReleaseName,
+	}
+	for i, f := range h.ValuesFiles {
+		fields["valuesFiles."+strconv.Itoa(i)] = f
+	}
+	return fields
+}
+
 var _ HelmChart = (*helmChart)(nil)
 
 func (c *Config) HelmCharts() []HelmChart {
@@ -50,3 +145,65 @@ func (c *Config) HelmCharts() []HelmChart {
 	}
 	return result
 }
+
+// helm limits release names to 53 characters, so that the names of the
+// resources it creates fit within 63.
+const maxReleaseNameLength = 53
+
+func validHelmChartsRule(cfg *Config) error {
+	for _, hc := range cfg.HelmCharts() {
+		h := hc.(*helmChart)
+		if h.Chart == "" {
+			return validationError("helm service %s must have a chart", h.GetName())
+		}
+		if h.IsOCI() && h.Repo != "" {
+			return validationError(
+				"helm service %s refers to an OCI chart and must not have a repo",
+				h.GetName(),
+			)
+		}
+		if !h.IsOCI() && h.Repo == "" {
+			return validationError(
+				"helm service %s must have a repo, or a chart starting with oci://",
+				h.GetName(),
+			)
+		}
+		if h.Timeout != "" {
+			if timeout, err := time.ParseDuration(h.Timeout); err != nil || timeout <= 0 {
+				return validationError(
+					"timeout %s of helm service %s must be a duration such as 5m",
+					h.Timeout,
+					h.GetName(),
+				)
+			}
+		}
+		if ns := h.GetNamespace(); ns != "" && len(validation.IsDNS1123Label(ns)) > 0 {
+			return validationError(
+				"namespace %s of helm service %s must consist of lower case letters, "+
+					"numbers and '-'",
+				ns,
+				h.GetName(),
+			)
+		}
+		if name := h.GetReleaseName(); name != "" &&
+			(len(validation.IsDNS1123Subdomain(name)) > 0 || len(name) > maxReleaseNameLength) {
+			return validationError(
+				"releaseName %s of helm service %s must consist of lower case letters, "+
+					"numbers, '-' and '.', and be at most %d characters",
+				name,
+				h.GetName(),
+				maxReleaseNameLength,
+			)
+		}
+		for _, f := range h.GetValuesFiles() {
+			if f == "" || path.IsAbs(f) {
+				return validationError(
+					"valuesFiles of helm service %s must be relative to %s",
+					h.GetName(),
+					defaultFileName,
+				)
+			}
+		}
+	}
+	return nil
+}
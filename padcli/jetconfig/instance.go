@@ -5,12 +5,16 @@ import (
 
 	"github.com/samber/lo"
 	"go.jetpack.io/launchpad/goutil/errorutil"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
 func validInstanceTypes() []string {
-	return lo.Map(lo.Values(InstanceType_name)[1:], func(v string, _ int) string {
-		return strings.ToLower(v)
+	values := lo.Without(maps.Keys(InstanceType_name), int32(InstanceType_UNKNOWN))
+	slices.Sort(values)
+	return lo.Map(values, func(v int32, _ int) string {
+		return strings.ToLower(InstanceType_name[v])
 	})
 }
 
@@ -33,7 +37,7 @@ func (i *InstanceType) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
-func (i *InstanceType) MarshalYAML() (any, error) {
+func (i InstanceType) MarshalYAML() (any, error) {
 	return strings.ToLower(i.String()), nil
 }
 
@@ -52,6 +56,12 @@ func (i *InstanceType) Compute() string {
 		return "1000m"
 	case InstanceType_MEDIUM_PLUS:
 		return "1500m"
+	case InstanceType_LARGE:
+		return "2000m"
+	case InstanceType_XLARGE:
+		return "4000m"
+	case InstanceType_XXLARGE:
+		return "8000m"
 	default:
 		return ""
 	}
@@ -72,6 +82,12 @@ func (i *InstanceType) Memory() string {
 		return "2048Mi"
 	case InstanceType_MEDIUM_PLUS:
 		return "3072Mi"
+	case InstanceType_LARGE:
+		return "4096Mi"
+	case InstanceType_XLARGE:
+		return "8192Mi"
+	case InstanceType_XXLARGE:
+		return "16384Mi"
 	default:
 		return ""
 	}
@@ -0,0 +1,60 @@
+package jetconfig
+
+// Internal is a service that is only reachable from inside the cluster, e.g.
+// a backend API used by the project's web services. It gets a ClusterIP
+// service with a stable DNS name, but no external hostname.
+type Internal interface {
+	Builder
+	Service
+	GetPort() int
+	GetHealthCheck() *HealthCheck
+}
+
+// instantiates a new Internal service for initcmd
+func (c *Config) AddNewInternalService(name string) Internal {
+	internalSvc := &internal{
+		Port: newEnvDependentField(DefaultAppPodPort),
+		service: service{
+			name: name,
+			Type: InternalType,
+		},
+	}
+	internalSvc.setParent(c)
+	c.Services = append(c.Services, internalSvc)
+	return internalSvc
+}
+
+type internal struct {
+	service       `yaml:",inline,omitempty"`
+	builder       `yaml:",inline,omitempty"`
+	healthChecker `yaml:",inline,omitempty"`
+	Port          envDependentField[int] `yaml:"port,omitempty"`
+}
+
+var _ Internal = (*internal)(nil)
+
+func (i *internal) setParent(p *Config) {
+	i.service.setParent(p)
+	i.builder.setParent(p)
+	i.healthChecker.setParent(p)
+}
+
+func (i *internal) GetPort() int {
+	if port := i.Port.Get(i.parent.env()); port != 0 {
+		return port
+	}
+	return DefaultAppPodPort
+}
+
+// InternalServices returns the internal services in the order they are
+// defined in the jetconfig. Each internal service is installed as its own app
+// release.
+func (c *Config) InternalServices() []Internal {
+	result := []Internal{}
+	for _, svc := range c.Services {
+		if i, ok := svc.(*internal); ok {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -9,21 +9,14 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"go.jetpack.io/launchpad/goutil/errorutil"
-	"go.jetpack.io/launchpad/padcli/terminal"
-	"go.jetpack.io/launchpad/pkg/jetlog"
-	"go.jetpack.io/launchpad/proto/api"
 	"gopkg.in/yaml.v3"
 )
 
 const JetpackEnvsecProvider = "jetpack"
 const defaultFileName = "launchpad.yaml"
 
-type EnvironmentFields struct {
-	// Default flags
-	Flags FlagSet `yaml:"flags,omitempty"`
-}
-
 type EnvsecFields struct {
 	Provider string `yaml:"provider,omitempty"`
 }
@@ -48,13 +41,37 @@ type Config struct {
 
 	Environment map[string]EnvironmentFields `yaml:"environment,omitempty"`
 
+	// Labels and Annotations are added to every resource of the project. See
+	// GetLabels.
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+
+	// Ingress configures how web services with custom domains are reached.
+	Ingress IngressFields `yaml:"ingress,omitempty"`
+
+	// Scheduling is the default for where the pods of every service run.
+	Scheduling envDependentField[Scheduling] `yaml:"scheduling,omitempty"`
+
 	Services services `yaml:"services,omitempty"`
 
+	// Unknown fields are errors by default to catch typos. Projects that need to
+	// be read by older versions of launchpad can opt out.
+	AllowUnknownFields bool `yaml:"allowUnknownFields,omitempty"`
+
 	// The file path to this jetconfig
 	Path string `yaml:"-"`
 
 	// part of app state but not saved to yaml
-	selectedEnvironment api.Environment
+	selectedEnvironment string
+	// the environment overlay file merged into this config, if any
+	overlayPath string
+	// the yaml document this config was loaded from, and the encoding of the
+	// config as loaded. Used to save only what changed.
+	doc         *yaml.Node
+	docValues   *yaml.Node
+	docContents []byte
+	// git variables for interpolation, resolved on first use
+	gitVars map[string]string
 }
 
 // isPathFormatAConfigFile returns true if the path format represents a config
@@ -100,7 +117,14 @@ func ConfigDir(path string) string {
 }
 
 func (cfg *Config) SaveConfig(path string) (string, error) {
-	marshalledYaml, err := cfg.marshalYaml()
+	if cfg.overlayPath != "" {
+		// Saving would write the overlay's values into the base config.
+		return "", errors.Errorf(
+			"cannot save config merged with %s. Load it with RequireBaseFromFileSystem instead",
+			filepath.Base(cfg.overlayPath),
+		)
+	}
+	marshalledYaml, err := cfg.patchedYaml()
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -115,11 +139,41 @@ func (cfg *Config) SaveConfig(path string) (string, error) {
 // - read launchpad.yaml at `path` in the file system
 // - populates the Config struct from the file's contents, via yaml unmarshalling
 // - upgrade the jetconfig with newer fields
+// - deep-merge the overlay for `env` (e.g. launchpad.prod.yaml) if present
 // - if config doesn't exist, it returns an error.
 func RequireFromFileSystem(
 	ctx context.Context,
 	path string,
-	env api.Environment,
+	env string,
+) (*Config, error) {
+	cfg, err := requireBaseFromFileSystem(ctx, path, env)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := cfg.applyOverlay(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cfg, cfg.validate()
+}
+
+// RequireBaseFromFileSystem is like RequireFromFileSystem but ignores
+// environment overlays. Use it to load a config that is going to be saved.
+func RequireBaseFromFileSystem(
+	ctx context.Context,
+	path string,
+	env string,
+) (*Config, error) {
+	cfg, err := requireBaseFromFileSystem(ctx, path, env)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cfg, cfg.validate()
+}
+
+func requireBaseFromFileSystem(
+	ctx context.Context,
+	path string,
+	env string,
 ) (*Config, error) {
 	filePath := configPath(path)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -134,17 +188,26 @@ func RequireFromFileSystem(
 		)
 	}
 
-	cfg := &Config{Path: filePath, selectedEnvironment: env}
-	err = cfg.loadConfigFromYamlContents(yamlContents)
+	yamlContents, err = upgrade(ctx, filePath, yamlContents)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if err = cfg.upgrade(ctx, filePath); err != nil {
+	cfg := &Config{Path: filePath, selectedEnvironment: strings.ToLower(env)}
+	err = cfg.loadConfigFromYamlContents(yamlContents)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return cfg, nil
+}
 
-	return cfg, cfg.validate()
+// env returns the selected environment. It's safe to call on a nil config
+// (e.g. services that were created without a parent).
+func (c *Config) env() string {
+	if c == nil {
+		return ""
+	}
+	return c.selectedEnvironment
 }
 
 func (c *Config) GetProjectID() string {
@@ -170,27 +233,17 @@ func (c *Config) Cronjobs() []Cron {
 	return result
 }
 
-// For now, at most one web service is expected
-// If no web service is specified, then returns empty-string for name, and nil for *Web
-//
-// NOTE: if we add a service.Name field, then this API improves
-// TODO DEV-966 enable multiple web-services. Change function's name and type.
-func (c *Config) WebService() (Web, error) {
-	var websvc Web
+// WebServices returns the web services in the order they are defined in the
+// jetconfig. The first web service is installed as the project's main app
+// release. Every other web service gets an app release of its own.
+func (c *Config) WebServices() []Web {
+	result := []Web{}
 	for _, svc := range c.Services {
 		if w, ok := svc.(*web); ok {
-			if websvc != nil {
-				return nil, errorutil.NewUserError(
-					"The jetconfig has multiple web services but " +
-						"jetpack currently only supports a single web service.",
-				)
-			}
-
-			websvc = w
+			result = append(result, w)
 		}
 	}
-
-	return websvc, nil
+	return result
 }
 
 func (c *Config) Builders() map[string]Builder {
@@ -207,9 +260,16 @@ func GetServiceTypes() []string {
 	return []string{
 		CronType,
 		WebType,
+		WorkerType,
+		InternalType,
 	}
 }
 
+// Marshal returns the config as yaml, as it would be saved.
+func (cfg *Config) Marshal() ([]byte, error) {
+	return cfg.marshalYaml()
+}
+
 // pulled out for testing
 func (cfg *Config) marshalYaml() ([]byte, error) {
 	var marshalledYaml bytes.Buffer
@@ -227,17 +287,73 @@ func (cfg *Config) marshalYaml() ([]byte, error) {
 // pulled out into its own function so we can write a test for
 // the custom marshalling that `services` do.
 func (cfg *Config) loadConfigFromYamlContents(yamlContents []byte) error {
-	// Request for Comment:
-	// Ideally, we would do strict so that any fields with a typo are caught.
-	// Not doing Strict because if the jetconfigStruct has already been loaded and some code calls
-	// this jetconfigStruct.LoadConfig again, then this will fail because the fields or keys will be duplicate.
-	if err := yaml.Unmarshal(yamlContents, cfg); err != nil {
-		return errors.Wrap(
-			err,
-			"failed to read jetconfig. yaml file due to mismatch of fields with the jetconfig struct",
-		)
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(yamlContents, root); err != nil {
+		return errors.Wrap(err, "failed to read jetconfig. yaml file is invalid")
 	}
-	return nil
+	if len(root.Content) == 0 {
+		// empty file
+		return nil
+	}
+
+	if err := cfg.decodeYamlNode(root); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := cfg.setDoc(root, yamlContents); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if cfg.AllowUnknownFields {
+		return nil
+	}
+	return errors.WithStack(unknownFieldsError(root.Content[0], cfg.fileName()))
+}
+
+// fileName returns the name of the config file, for messages.
+func (cfg *Config) fileName() string {
+	if cfg.Path == "" {
+		return defaultFileName
+	}
+	return filepath.Base(cfg.Path)
+}
+
+func (cfg *Config) decodeYamlNode(node *yaml.Node) error {
+	// Start from a fresh struct so that loading a config that was already loaded
+	// doesn't accumulate services or keep fields removed from the file.
+	*cfg = Config{
+		Path:                cfg.Path,
+		selectedEnvironment: cfg.selectedEnvironment,
+		doc:                 cfg.doc,
+		docValues:           cfg.docValues,
+		docContents:         cfg.docContents,
+	}
+	return errors.Wrap(
+		node.Decode(cfg),
+		"failed to read jetconfig. yaml file due to mismatch of fields with the jetconfig struct",
+	)
+}
+
+// unknownFieldsError returns a user error listing every key in the yaml that
+// doesn't map to a field, so that typos don't silently change what is deployed.
+func unknownFieldsError(root *yaml.Node, configFileName string) error {
+	problems := lo.Filter(
+		validateNode(configSchemaFor(environmentNames(root)), root, nil),
+		func(p *ValidationError, _ int) bool { return p.isUnknownField },
+	)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	lines := lo.Map(problems, func(p *ValidationError, _ int) string {
+		return fmt.Sprintf("  %s:%s", configFileName, p)
+	})
+	return errorutil.NewUserErrorf(
+		"%s has unknown fields:\n%s\n\nFix or remove these fields, or add "+
+			"`allowUnknownFields: true` to %s to ignore them.",
+		configFileName,
+		strings.Join(lines, "\n"),
+		configFileName,
+	)
 }
 
 func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -253,10 +369,10 @@ func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// HasDeployment returns true if any service runs as a long-running deployment,
+// i.e. a web service, internal service or worker.
 func (cfg *Config) HasDeployment() (bool, error) {
-	// We can evolve this to other types of services (like internal)
-	w, err := cfg.WebService()
-	return w != nil, errors.WithStack(err)
+	return len(cfg.WebServices())+len(cfg.InternalServices())+len(cfg.Workers()) > 0, nil
 }
 
 func (cfg *Config) GetProjectName() string {
@@ -268,71 +384,15 @@ func (c *Config) GetProjectNameWithSlug() string {
 }
 
 func (cfg *Config) GetInstanceName() string {
-	// For now we assume one web service, so the instance name
-	// will simply be <projectName>-<serviceName>.
-	w, err := cfg.WebService()
-	if err != nil || w == nil {
+	// The main app release is named after the first web service, so the
+	// instance name will simply be <projectName>-<serviceName>. Additional
+	// web services use their own unique names (see GetUniqueName).
+	websvcs := cfg.WebServices()
+	if len(websvcs) == 0 {
 		// No webservice found. Fallback to project name.
 		return cfg.GetProjectName()
 	}
-
-	projectName := cfg.GetProjectName()
-	serviceName := w.GetName()
-	if strings.Contains(serviceName, projectName) {
-		return serviceName
-	}
-
-	return fmt.Sprintf("%s-%s", projectName, serviceName)
-}
-
-// upgrade will edit the schema to match the latest version of the schema,
-// and write the newer Config to the launchpad.yaml file.
-// upgrade is called after `cfg Config` has been populated from the launchpad.yaml file.
-//
-// It focuses on upgrading from the latest-minus-one version to the latest version. It doesn't
-// strive be compatible for much older versions: users are expected to auto-upgrade
-// because they regularly deploy jetpack projects, or reach out for support if they
-// need to manually update a very old project. This reduces our engineering burden
-// of maintaining backwards compatibility for an alpha product, which rapidly
-// evolves.
-//
-// NOTE: we'll need to periodically update this function to reflect changes to jetconfig
-// and also purge upgrades for fields once they have been present for a long time.
-func (cfg *Config) upgrade(ctx context.Context, filePath string) error {
-	configFileName := ConfigName(filePath)
-	if isUnsupported, err := isVersionLessThanMinimumSupported(cfg.ConfigVersion); err != nil {
-		return errors.WithStack(err)
-	} else if isUnsupported {
-		return errorutil.NewUserErrorf(
-			fmt.Sprintf("The configVersion in %s is too old to auto-upgrade. "+
-				"Please reach out for support, or consult docs at https://www.jetpack.io/launchpad/docs/reference/launchpad.yaml-reference/", configFileName))
-	}
-
-	if needsUpgrade, err := doesVersionNeedUpgrade(cfg.ConfigVersion); err != nil {
-		return errors.WithStack(err)
-	} else if !needsUpgrade {
-		// Nothing to upgrade
-		return nil
-	}
-
-	// stop the upgrade if we are in a non-interactive terminal.
-	if !terminal.IsInteractive() {
-		return errorutil.NewUserErrorf(
-			fmt.Sprintf("The configVersion in %s needs an upgrade. Please run `jetpack config upgrade`.", configFileName))
-	}
-
-	cfg.ConfigVersion = Versions.Prod()
-
-	// Other upgraded fields go here:
-
-	jetlog.Logger(ctx).WarningPrintf(
-		"Upgraded your %s to the latest version %s. "+
-			"Please commit this change to your repository.",
-		configFileName,
-		Versions.Prod(),
-	)
-	_, err := cfg.SaveConfig(filePath)
-	return errors.Wrap(err, fmt.Sprintf("failed to auto-upgrade %s", configFileName))
+	return websvcs[0].GetUniqueName()
 }
 
 func (c *Config) HasDefaultFileName() bool {
@@ -29,6 +29,9 @@ const (
 	InstanceType_SMALL       InstanceType = 3
 	InstanceType_MEDIUM      InstanceType = 4
 	InstanceType_MEDIUM_PLUS InstanceType = 5
+	InstanceType_LARGE       InstanceType = 6
+	InstanceType_XLARGE      InstanceType = 7
+	InstanceType_XXLARGE     InstanceType = 8
 )
 
 // Enum value maps for InstanceType.
@@ -40,6 +43,9 @@ var (
 		3: "SMALL",
 		4: "MEDIUM",
 		5: "MEDIUM_PLUS",
+		6: "LARGE",
+		7: "XLARGE",
+		8: "XXLARGE",
 	}
 	InstanceType_value = map[string]int32{
 		"UNKNOWN":     0,
@@ -48,6 +54,9 @@ var (
 		"SMALL":       3,
 		"MEDIUM":      4,
 		"MEDIUM_PLUS": 5,
+		"LARGE":       6,
+		"XLARGE":      7,
+		"XXLARGE":     8,
 	}
 )
 
@@ -83,22 +92,25 @@ var File_padcli_jetconfig_jetconfig_proto protoreflect.FileDescriptor
 var file_padcli_jetconfig_jetconfig_proto_rawDesc = []byte{
 	0x0a, 0x20, 0x70, 0x61, 0x64, 0x63, 0x6c, 0x69, 0x2f, 0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66,
 	0x69, 0x67, 0x2f, 0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x09, 0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2a, 0x58, 0x0a,
+	0x74, 0x6f, 0x12, 0x09, 0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2a, 0x7c, 0x0a,
 	0x0c, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a,
 	0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x41,
 	0x4e, 0x4f, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x4d, 0x49, 0x43, 0x52, 0x4f, 0x10, 0x02, 0x12,
 	0x09, 0x0a, 0x05, 0x53, 0x4d, 0x41, 0x4c, 0x4c, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x45,
 	0x44, 0x49, 0x55, 0x4d, 0x10, 0x04, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x45, 0x44, 0x49, 0x55, 0x4d,
-	0x5f, 0x50, 0x4c, 0x55, 0x53, 0x10, 0x05, 0x42, 0x8d, 0x01, 0x0a, 0x0d, 0x63, 0x6f, 0x6d, 0x2e,
-	0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x42, 0x0e, 0x4a, 0x65, 0x74, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x28, 0x67, 0x6f, 0x2e,
-	0x6a, 0x65, 0x74, 0x70, 0x61, 0x63, 0x6b, 0x2e, 0x69, 0x6f, 0x2f, 0x6c, 0x61, 0x75, 0x6e, 0x63,
-	0x68, 0x70, 0x61, 0x64, 0x2f, 0x70, 0x61, 0x64, 0x63, 0x6c, 0x69, 0x2f, 0x6a, 0x65, 0x74, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0xa2, 0x02, 0x03, 0x4a, 0x58, 0x58, 0xaa, 0x02, 0x09, 0x4a, 0x65,
-	0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0xca, 0x02, 0x09, 0x4a, 0x65, 0x74, 0x63, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0xe2, 0x02, 0x15, 0x4a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5c,
-	0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x09, 0x4a, 0x65,
-	0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x5f, 0x50, 0x4c, 0x55, 0x53, 0x10, 0x05, 0x12, 0x09, 0x0a, 0x05, 0x4c, 0x41, 0x52, 0x47, 0x45,
+	0x10, 0x06, 0x12, 0x0a, 0x0a, 0x06, 0x58, 0x4c, 0x41, 0x52, 0x47, 0x45, 0x10, 0x07, 0x12, 0x0b,
+	0x0a, 0x07, 0x58, 0x58, 0x4c, 0x41, 0x52, 0x47, 0x45, 0x10, 0x08, 0x42, 0x8d, 0x01, 0x0a, 0x0d,
+	0x63, 0x6f, 0x6d, 0x2e, 0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x42, 0x0e, 0x4a,
+	0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a,
+	0x28, 0x67, 0x6f, 0x2e, 0x6a, 0x65, 0x74, 0x70, 0x61, 0x63, 0x6b, 0x2e, 0x69, 0x6f, 0x2f, 0x6c,
+	0x61, 0x75, 0x6e, 0x63, 0x68, 0x70, 0x61, 0x64, 0x2f, 0x70, 0x61, 0x64, 0x63, 0x6c, 0x69, 0x2f,
+	0x6a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0xa2, 0x02, 0x03, 0x4a, 0x58, 0x58, 0xaa,
+	0x02, 0x09, 0x4a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0xca, 0x02, 0x09, 0x4a, 0x65,
+	0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0xe2, 0x02, 0x15, 0x4a, 0x65, 0x74, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea,
+	0x02, 0x09, 0x4a, 0x65, 0x74, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1,10 +1,18 @@
 package jetconfig
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/yaml.v3"
@@ -16,6 +24,9 @@ var jetconfigYaml_v0_1_2 string
 //go:embed jetconfig_test_multi_url.yaml
 var jetconfig_test_multi_url string
 
+//go:embed jetconfig_test_multi_web.yaml
+var jetconfigYaml_multi_web string
+
 type Suite struct {
 	suite.Suite
 }
@@ -30,7 +41,6 @@ func (s *Suite) TestLoad() {
 		yamlContents string
 	}{
 		{"0.1.2", jetconfigYaml_v0_1_2},
-		// Made new config because we don't support 2 web services in the same config
 		{"0.1.2-multi-url", jetconfig_test_multi_url},
 	}
 
@@ -59,12 +69,279 @@ func loadConfigOfVersion(t *testing.T, yamlContents string) {
 		}
 	}
 
-	svc, err := cfg.WebService()
-	req.NoError(err)
-	if svc.GetName() == "ghost" {
-		req.Equal(svc.GetImage(), "ghost:4.26.1-alpine")
+	websvcs := cfg.WebServices()
+	req.Equal(1, len(websvcs))
+	if websvcs[0].GetName() == "ghost" {
+		req.Equal(websvcs[0].GetImage(), "ghost:4.26.1-alpine")
 	} else {
-		req.Fail("unexpected name", "name %s", svc.GetName())
+		req.Fail("unexpected name", "name %s", websvcs[0].GetName())
+	}
+}
+
+func (s *Suite) TestMultipleWebServices() {
+	req := s.Require()
+	cfg := &Config{}
+	err := cfg.loadConfigFromYamlContents([]byte(jetconfigYaml_multi_web))
+	req.NoError(err)
+
+	websvcs := cfg.WebServices()
+	req.Len(websvcs, 2)
+	req.Equal("api", websvcs[0].GetName())
+	req.Equal(8080, websvcs[0].GetPort())
+	req.Equal("admin", websvcs[1].GetName())
+	req.Equal(3000, websvcs[1].GetPort())
+	req.Equal("node:18-alpine", websvcs[1].GetImage())
+
+	// The first web service owns the main app release.
+	req.Equal("py-dockerfile-api", cfg.GetInstanceName())
+	req.Equal("py-dockerfile-admin", websvcs[1].GetUniqueName())
+}
+
+func (s *Suite) TestUnknownFields() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+services:
+  date-printer-cron:
+    type: cron
+    image: busybox:latest
+    schedul: "*/1 * * * *"
+    buildComand: make
+`
+	cfg := &Config{}
+	err := cfg.loadConfigFromYamlContents([]byte(yamlContents))
+	req.Error(err)
+	req.Contains(err.Error(), `8:5: unknown field "schedul". Did you mean "schedule"?`)
+	req.Contains(err.Error(), `9:5: unknown field "buildComand". Did you mean "buildCommand"?`)
+
+	cfg = &Config{}
+	err = cfg.loadConfigFromYamlContents([]byte("allowUnknownFields: true\n" + yamlContents))
+	req.NoError(err)
+	req.Len(cfg.Cronjobs(), 1)
+}
+
+func (s *Suite) TestReload() {
+	req := s.Require()
+	cfg := &Config{}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(jetconfigYaml_multi_web)))
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(jetconfigYaml_multi_web)))
+	req.Len(cfg.WebServices(), 2)
+}
+
+func (s *Suite) TestOverlay() {
+	req := s.Require()
+	dir := s.T().TempDir()
+	base := "cluster: my-cluster\n" + jetconfigYaml_multi_web
+	req.NoError(os.WriteFile(filepath.Join(dir, "launchpad.yaml"), []byte(base), 0666))
+	overlay := `cluster: my-prod-cluster
+services:
+  api:
+    instance: medium
+  worker-cron:
+    type: cron
+    schedule: "* * * * *"
+`
+	req.NoError(os.WriteFile(filepath.Join(dir, "launchpad.prod.yaml"), []byte(overlay), 0666))
+
+	cfg, err := RequireFromFileSystem(context.Background(), dir, "prod")
+	req.NoError(err)
+	req.Equal(filepath.Join(dir, "launchpad.prod.yaml"), cfg.OverlayPath())
+	req.Equal("my-prod-cluster", cfg.Cluster)
+	websvcs := cfg.WebServices()
+	req.Len(websvcs, 2)
+	req.Equal(InstanceType_MEDIUM, *websvcs[0].GetInstanceType())
+	// fields not in the overlay are kept
+	req.Equal(3000, websvcs[1].GetPort())
+	req.Len(cfg.Cronjobs(), 1)
+	_, err = cfg.SaveConfig(dir)
+	req.Error(err)
+
+	cfg, err = RequireFromFileSystem(context.Background(), dir, "dev")
+	req.NoError(err)
+	req.Empty(cfg.OverlayPath())
+	req.Equal("my-cluster", cfg.Cluster)
+	req.Equal(InstanceType_SMALL, *cfg.WebServices()[0].GetInstanceType())
+	req.Empty(cfg.Cronjobs())
+
+	req.NoError(os.WriteFile(
+		filepath.Join(dir, "launchpad.prod.yaml"),
+		[]byte("services:\n  api:\n    instanse: medium\n"),
+		0666,
+	))
+	_, err = RequireFromFileSystem(context.Background(), dir, "prod")
+	req.ErrorContains(err, `launchpad.prod.yaml:3:5: unknown field "instanse"`)
+}
+
+func (s *Suite) TestEnvironmentDependentFields() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+services:
+  api:
+    type: web
+    instance:
+      dev: nano
+      prod: medium
+    port:
+      dev: 3000
+  date-printer-cron:
+    type: cron
+    image: busybox:latest
+    schedule:
+      dev: "0 * * * *"
+      prod: "* * * * *"
+    command:
+      dev: [echo, dev]
+      prod: [echo, prod]
+    concurrencyPolicy: Forbid
+`
+	for _, tc := range []struct {
+		env      string
+		instance InstanceType
+		port     int
+		schedule string
+		command  []string
+	}{
+		{"dev", InstanceType_NANO, 3000, "0 * * * *", []string{"echo", "dev"}},
+		{"prod", InstanceType_MEDIUM, DefaultAppPodPort, "* * * * *", []string{"echo", "prod"}},
+	} {
+		cfg := &Config{selectedEnvironment: tc.env}
+		req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+
+		websvc := cfg.WebServices()[0]
+		req.Equal(tc.instance, *websvc.GetInstanceType())
+		req.Equal(tc.port, websvc.GetPort())
+
+		cron := cfg.Cronjobs()[0]
+		req.Equal(tc.schedule, cron.GetSchedule())
+		req.Equal(tc.command, cron.GetCommand())
+		req.Equal("Forbid", cron.GetConcurrencyPolicy())
+		req.Equal("busybox:latest", cron.GetImage())
+	}
+
+	// staging isn't set, so these fall back to the defaults
+	cfg := &Config{selectedEnvironment: "staging"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.Nil(cfg.WebServices()[0].GetInstanceType())
+	req.Equal(DefaultAppPodPort, cfg.WebServices()[0].GetPort())
+}
+
+func (s *Suite) TestDeclaredEnvironments() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+environment:
+  qa:
+    namespace: "{{.Project}}-{{.Environment}}"
+    jobRetention: 1h
+  perf:
+    tagPrefix: load-
+  prod:
+    jobRetention: 48h
+services:
+  api:
+    type: web
+    instance:
+      qa: small
+      perf: medium
+    resources:
+      qa:
+        limits:
+          memory: 1Gi
+      prod:
+        limits:
+          memory: 4Gi
+    env:
+      LOG_LEVEL:
+        qa: debug
+        prod: info
+      API_KEY:
+        qa:
+          secret: QA_API_KEY
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "qa"))
+	cfg := &Config{selectedEnvironment: "qa"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+	req.Equal([]string{"dev", "perf", "prod", "qa", "staging"}, cfg.Environments())
+	req.Equal("qa-", cfg.ImageTagPrefix())
+	ns, err := cfg.Namespace()
+	req.NoError(err)
+	req.Equal("py-dockerfile-qa", ns)
+	req.Equal(time.Hour, cfg.JobRetention())
+	req.Equal(InstanceType_SMALL, *cfg.WebServices()[0].GetInstanceType())
+	// struct and map fields take declared environments as keys too
+	req.Equal(Quantity("1Gi"), cfg.WebServices()[0].GetResources().Limits.Memory)
+	req.Equal(map[string]EnvVar{
+		"LOG_LEVEL": {Value: "debug"},
+		"API_KEY":   {Secret: "QA_API_KEY"},
+	}, cfg.WebServices()[0].GetEnv())
+
+	cfg = &Config{selectedEnvironment: "perf"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.Equal("load-", cfg.ImageTagPrefix())
+	ns, err = cfg.Namespace()
+	req.NoError(err)
+	req.Empty(ns)
+	req.Equal(24*time.Hour, cfg.JobRetention())
+
+	// built-in environments keep their defaults unless overridden
+	cfg = &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.Equal("dev-", cfg.ImageTagPrefix())
+	req.Equal(10*time.Minute, cfg.JobRetention())
+	cfg = &Config{selectedEnvironment: "prod"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.Equal("prod-", cfg.ImageTagPrefix())
+	req.Equal(48*time.Hour, cfg.JobRetention())
+
+	cfg = &Config{selectedEnvironment: "demo"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.ErrorContains(cfg.validate(), "Environment demo is not declared")
+
+	cfg = &Config{selectedEnvironment: "qa"}
+	req.NoError(cfg.loadConfigFromYamlContents(
+		[]byte(strings.Replace(yamlContents, "perf: medium", "prdo: medium", 1)),
+	))
+	req.ErrorContains(cfg.validate(), "Service api uses environment prdo, which is not declared")
+
+	// keys of struct fields that are not environments are unknown fields
+	cfg = &Config{selectedEnvironment: "qa"}
+	req.ErrorContains(
+		cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, "      qa:\n        limits:", "      demo:\n        limits:", 1)),
+		),
+		`unknown field "demo"`,
+	)
+
+	// Configs decode independently of the environments that other configs
+	// declare, e.g. when loaded at the same time
+	undeclared := yamlContents[:strings.Index(yamlContents, "environment:")] +
+		yamlContents[strings.Index(yamlContents, "services:"):]
+	loadErrs := make([]error, 20)
+	var wg sync.WaitGroup
+	for i := range loadErrs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			contents := lo.Ternary(i%2 == 0, yamlContents, undeclared)
+			cfg := &Config{selectedEnvironment: "qa"}
+			if loadErrs[i] = cfg.loadConfigFromYamlContents([]byte(contents)); loadErrs[i] == nil {
+				loadErrs[i] = cfg.validate()
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range loadErrs {
+		if i%2 == 0 {
+			req.NoError(err)
+		} else {
+			req.ErrorContains(err, `unknown field "qa"`)
+		}
 	}
 }
 
@@ -108,3 +385,1217 @@ func saveConfigOfVersion(t *testing.T, yamlContents string) {
 	// finally, we compare the original versus saved jetconfig contents
 	req.Equal(mapControl, mapTest)
 }
+
+func (s *Suite) TestWorkers() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+services:
+  consumer:
+    type: worker
+    image: node:18-alpine
+    command: [node, consumer.js]
+    instance: MEDIUM
+    replicas:
+      dev: 1
+      prod: 3
+`
+	cfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+
+	workers := cfg.Workers()
+	req.Len(workers, 1)
+	req.Empty(cfg.WebServices())
+	req.Equal("consumer", workers[0].GetName())
+	req.Equal("node:18-alpine", workers[0].GetImage())
+	req.Equal([]string{"node", "consumer.js"}, workers[0].GetCommand())
+	req.Equal(InstanceType_MEDIUM, *workers[0].GetInstanceType())
+	req.Equal(3, workers[0].GetReplicas())
+
+	cfg.selectedEnvironment = "staging"
+	req.Equal(defaultWorkerReplicas, workers[0].GetReplicas())
+}
+
+func (s *Suite) TestResources() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    instance: small
+    resources:
+      limits:
+        cpu: 1
+        memory: 2Gi
+  batch:
+    type: job
+    instance: xlarge
+    command: [java, -jar, batch.jar]
+    resources:
+      requests:
+        memory: 12Gi
+  consumer:
+    type: worker
+    resources:
+      prod:
+        limits:
+          memory: 256Mi
+`
+	cfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	req.Equal(
+		map[string]any{
+			"requests": map[string]any{"cpu": "500m", "memory": "1024Mi"},
+			"limits":   map[string]any{"cpu": "1", "memory": "2Gi"},
+		},
+		cfg.WebServices()[0].GetResources().Values(),
+	)
+	req.Equal(
+		map[string]any{"requests": map[string]any{"cpu": "4000m", "memory": "12Gi"}},
+		cfg.Jobs()[0].GetResources().Values(),
+	)
+	// Without an instance type, requests default to the limit
+	req.Equal(
+		map[string]any{
+			"requests": map[string]any{"cpu": "250m", "memory": "256Mi"},
+			"limits":   map[string]any{"memory": "256Mi"},
+		},
+		cfg.Workers()[0].GetResources().Values(),
+	)
+
+	cfg.selectedEnvironment = "dev"
+	req.Equal(
+		map[string]any{"requests": map[string]any{"cpu": "250m", "memory": "512Mi"}},
+		cfg.Workers()[0].GetResources().Values(),
+	)
+
+	cfg = &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(strings.Replace(
+		yamlContents, "memory: 2Gi", "memory: 512Mi", 1,
+	))))
+	req.ErrorContains(cfg.validate(), "api memory request 1024Mi is greater than its limit 512Mi")
+
+	cfg = &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(strings.Replace(
+		yamlContents, "memory: 12Gi", "memory: 12GB!", 1,
+	))))
+	req.ErrorContains(cfg.validate(), `batch memory request "12GB!" is not a valid quantity`)
+}
+
+func (s *Suite) TestHealthChecks() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    healthCheck:
+      http:
+        path: /healthz
+      startupSeconds: 60
+  users:
+    type: internal
+    healthCheck:
+      tcp: {}
+  consumer:
+    type: worker
+    healthCheck:
+      dev:
+        exec: [cat, /tmp/ready]
+`
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	req.Equal(
+		&HealthCheck{HTTP: &HTTPHealthCheck{Path: "/healthz"}, StartupSeconds: 60},
+		cfg.WebServices()[0].GetHealthCheck(),
+	)
+	req.Equal(&HealthCheck{TCP: &TCPHealthCheck{}}, cfg.InternalServices()[0].GetHealthCheck())
+	req.Equal(
+		&HealthCheck{Exec: []string{"cat", "/tmp/ready"}},
+		cfg.Workers()[0].GetHealthCheck(),
+	)
+	cfg.selectedEnvironment = "prod"
+	req.Nil(cfg.Workers()[0].GetHealthCheck())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"path: /healthz", "path: healthz", "healthCheck path healthz of service api must start with /"},
+		{"exec: [cat, /tmp/ready]", "tcp: {}", "healthCheck tcp of service consumer needs a port"},
+		{
+			"tcp: {}",
+			"tcp: {}\n      exec: [ls]",
+			"healthCheck of service users must have exactly one of http, tcp or exec",
+		},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestWebReplicas() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    replicas:
+      dev: 1
+      prod:
+        min: 2
+        max: 10
+        targetMemory: 70
+  admin:
+    type: web
+    replicas:
+      max: 3
+  docs:
+    type: web
+`
+	cfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	websvcs := cfg.WebServices()
+	req.Equal(&Replicas{Min: 2, Max: 10, TargetMemory: 70}, websvcs[0].GetReplicas())
+	req.True(websvcs[0].GetReplicas().IsAutoscaled())
+	// min defaults to 1 and targetCPU to 80%
+	req.Equal(&Replicas{Min: 1, Max: 3, TargetCPU: 80}, websvcs[1].GetReplicas())
+	req.Nil(websvcs[2].GetReplicas())
+	req.False(websvcs[2].GetReplicas().IsAutoscaled())
+
+	cfg.selectedEnvironment = "dev"
+	req.Equal(&Replicas{Min: 1}, websvcs[0].GetReplicas())
+	req.False(websvcs[0].GetReplicas().IsAutoscaled())
+
+	// fixed counts stay numbers
+	replicas, err := cfg.GetField("services.api.replicas.dev")
+	req.NoError(err)
+	req.Equal("1", replicas)
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"max: 10", "max: 1", "replicas max 1 of web service api must not be less than min 2"},
+		{"max: 3", "max: 3\n      min: 3\n      targetCPU: 50", "only apply when max is greater than min"},
+	} {
+		cfg := &Config{selectedEnvironment: "prod"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestJobPhases() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+  migrate:
+    type: job
+    command: [python, manage.py, migrate]
+    runBefore: [api]
+  seed:
+    type: job
+    phase: pre-deploy
+  report:
+    type: job
+`
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	jobs := lo.SliceToMap(cfg.Jobs(), func(j Job) (string, Job) { return j.GetName(), j })
+	req.Equal([]string{"api"}, jobs["migrate"].GetRunBefore())
+	req.True(jobs["migrate"].IsPreDeploy())
+	req.True(jobs["seed"].IsPreDeploy())
+	req.False(jobs["report"].IsPreDeploy())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"phase: pre-deploy", "phase: later", "phase later of job seed should be one of"},
+		{
+			"runBefore: [api]",
+			"runBefore: [api]\n    phase: deploy",
+			"job migrate has runBefore, so its phase must be pre-deploy",
+		},
+		{
+			"runBefore: [api]",
+			"runBefore: [web]",
+			"runBefore of job migrate refers to service web, which does not exist",
+		},
+		{
+			"runBefore: [api]",
+			"runBefore: [seed]",
+			"runBefore can only refer to web, internal and worker services",
+		},
+		{
+			"report:",
+			"Pre_Deploy:",
+			"service name Pre_Deploy is reserved for the release that runs pre-deploy jobs",
+		},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestJobSpec() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  resize-images:
+    type: job
+    backoffLimit: 0
+    activeDeadlineSeconds: 3600
+    parallelism: 4
+    completions: 20
+    ttlSecondsAfterFinished:
+      dev: 60
+  report:
+    type: job
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	jobs := lo.SliceToMap(cfg.Jobs(), func(j Job) (string, Job) { return j.GetName(), j })
+	resize := jobs["resize-images"]
+	req.Equal(lo.ToPtr(0), resize.GetBackoffLimit())
+	req.Equal(lo.ToPtr(3600), resize.GetActiveDeadlineSeconds())
+	req.Equal(lo.ToPtr(4), resize.GetParallelism())
+	req.Equal(lo.ToPtr(20), resize.GetCompletions())
+	req.Equal(lo.ToPtr(60), resize.GetTTLSecondsAfterFinished())
+	report := jobs["report"]
+	req.Nil(report.GetBackoffLimit())
+	req.Nil(report.GetActiveDeadlineSeconds())
+	req.Nil(report.GetParallelism())
+	req.Nil(report.GetCompletions())
+	req.Nil(report.GetTTLSecondsAfterFinished())
+
+	prodCfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(prodCfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.Nil(prodCfg.Jobs()[0].GetTTLSecondsAfterFinished())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"backoffLimit: 0", "backoffLimit: -1", "backoffLimit of job resize-images must be at least 0"},
+		{"activeDeadlineSeconds: 3600", "activeDeadlineSeconds: 0", "activeDeadlineSeconds of job resize-images must be at least 1"},
+		{"parallelism: 4", "parallelism: 0", "parallelism of job resize-images must be at least 1"},
+		{"completions: 20", "completions: -2", "completions of job resize-images must be at least 1"},
+		{"dev: 60", "dev: -60", "ttlSecondsAfterFinished of job resize-images must be at least 0"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestServiceEnv() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    env:
+      LOG_LEVEL: info
+      PORT: 8080
+      API_URL:
+        dev: https://api.dev.example.com
+        prod: https://api-${ENVIRONMENT}.example.com
+      DATABASE_URL:
+        secret: PROD_DATABASE_URL
+    configFiles:
+      - path: config/nginx.conf
+        mountPath: /etc/nginx/nginx.conf
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "prod"))
+
+	cfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	api := cfg.WebServices()[0]
+	req.Equal(
+		map[string]EnvVar{
+			"LOG_LEVEL":    {Value: "info"},
+			"PORT":         {Value: "8080"},
+			"API_URL":      {Value: "https://api-prod.example.com"},
+			"DATABASE_URL": {Secret: "PROD_DATABASE_URL"},
+		},
+		api.GetEnv(),
+	)
+	req.Equal(
+		[]ConfigFile{{Path: "config/nginx.conf", MountPath: "/etc/nginx/nginx.conf"}},
+		api.GetConfigFiles(),
+	)
+	req.Equal("etc_nginx_nginx.conf", api.GetConfigFiles()[0].Key())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"LOG_LEVEL", "LOG-LEVEL", "env LOG-LEVEL of service api is not a valid environment variable name"},
+		{
+			"secret: PROD_DATABASE_URL",
+			"secret: PROD_DATABASE_URL\n        value: postgres://localhost",
+			"env DATABASE_URL of service api must have either a value or a secret, not both",
+		},
+		{"mountPath: /etc", "mountPath: etc", "must be an absolute path"},
+		{"path: config/nginx.conf", "path: /config/nginx.conf", "must have a path relative to"},
+	} {
+		cfg := &Config{selectedEnvironment: "prod"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestVolumes() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  db:
+    type: worker
+    volumes:
+      - name: data
+        mountPath: /var/lib/postgresql/data
+        size: 10Gi
+        storageClass: ssd
+  uploads:
+    type: web
+    volumes:
+      - name: files
+        mountPath: /srv/files
+        size: 1Gi
+        accessMode: ReadWriteMany
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	db := cfg.Workers()[0].GetVolumes()
+	req.Equal(
+		[]Volume{{
+			Name:         "data",
+			MountPath:    "/var/lib/postgresql/data",
+			Size:         "10Gi",
+			StorageClass: "ssd",
+		}},
+		db,
+	)
+	req.Equal(ReadWriteOnce, db[0].GetAccessMode())
+	req.Equal(ReadWriteMany, cfg.WebServices()[0].GetVolumes()[0].GetAccessMode())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"name: data", "name: Data", `volume name "Data" of service db must consist of`},
+		{"size: 10Gi", "size: lots", "volume data of service db must have a size such as 10Gi"},
+		{"mountPath: /srv/files", "mountPath: files", "volume files of service uploads must have an absolute mountPath"},
+		{"accessMode: ReadWriteMany", "accessMode: rwx", "accessMode rwx of volume files of service uploads should be one of"},
+		{
+			"storageClass: ssd",
+			"storageClass: ssd\n    replicas: 2",
+			"volume data of service db is ReadWriteOnce, so it can't be mounted by more than one replica",
+		},
+		{
+			"accessMode: ReadWriteMany",
+			"accessMode: ReadWriteOncePod\n    replicas:\n      min: 1\n      max: 3",
+			"volume files of service uploads is ReadWriteOncePod",
+		},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestContainers() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    sidecars:
+      - name: cloud-sql-proxy
+        image: gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.1.0
+        command: [/cloud-sql-proxy, "${PROJECT_ID}:db"]
+        env:
+          LOG_LEVEL: debug
+        ports: [5432]
+        resources:
+          limits:
+            memory: 256Mi
+    initContainers:
+      - name: wait-for-redis
+        image: busybox:1.36
+        command: [sh, -c, "until nc -z redis 6379; do sleep 1; done"]
+  migrate:
+    type: job
+    initContainers:
+      - name: wait-for-db
+        image: busybox:1.36
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	api := cfg.WebServices()[0]
+	req.Equal(
+		[]Container{{
+			Name:    "cloud-sql-proxy",
+			Image:   "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.1.0",
+			Command: []string{"/cloud-sql-proxy", "proj_4pss8bskaTPOWzuhyY7cfL:db"},
+			Env:     map[string]string{"LOG_LEVEL": "debug"},
+			Ports:   []int{5432},
+			Resources: Resources{
+				Limits: ResourceList{Memory: "256Mi"},
+			},
+		}},
+		api.GetSidecars(),
+	)
+	req.Len(api.GetInitContainers(), 1)
+	req.Equal("wait-for-db", cfg.Jobs()[0].GetInitContainers()[0].Name)
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"name: wait-for-redis", "name: cloud-sql-proxy", "container cloud-sql-proxy of service api is declared more than once"},
+		{"name: wait-for-db", "name: Wait", `container name "Wait" of service migrate must consist of`},
+		{"image: busybox:1.36\n        command", "command", "container wait-for-redis of service api must have an image"},
+		{"ports: [5432]", "ports: [0]", "port 0 of container cloud-sql-proxy of service api must be between"},
+		{"memory: 256Mi", "memory: lots", "resources of container cloud-sql-proxy of service api must be valid"},
+		{"LOG_LEVEL: debug", "LOG-LEVEL: debug", "env LOG-LEVEL of container cloud-sql-proxy"},
+		{"    initContainers:\n      - name: wait-for-db", "    sidecars:\n      - name: wait-for-db", "service migrate runs to completion and can't have sidecars"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestHelmCharts() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  redis:
+    type: helm
+    repo: https://charts.bitnami.com/bitnami
+    chart: redis
+    version: 17.11.3
+    namespace: cache
+    releaseName: redis-${ENVIRONMENT}
+    values:
+      architecture: standalone
+      auth:
+        enabled: false
+    valuesFiles:
+      - redis.yaml
+      - redis-${ENVIRONMENT}.yaml
+    wait: true
+    timeout: 10m
+  postgres:
+    type: helm
+    chart: oci://registry-1.docker.io/bitnamicharts/postgresql
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	charts := cfg.HelmCharts()
+	req.Len(charts, 2)
+	redis, postgres := charts[0], charts[1]
+	req.Equal("redis", redis.GetChart())
+	req.Equal("17.11.3", redis.GetVersion())
+	req.Equal("cache", redis.GetNamespace())
+	req.Equal("redis-dev", redis.GetReleaseName())
+	req.Equal(
+		map[string]any{"architecture": "standalone", "auth": map[string]any{"enabled": false}},
+		redis.GetValues(),
+	)
+	req.Equal([]string{"redis.yaml", "redis-dev.yaml"}, redis.GetValuesFiles())
+	req.True(redis.GetWait())
+	req.Equal(10*time.Minute, redis.GetTimeout())
+	req.False(redis.IsOCI())
+
+	req.True(postgres.IsOCI())
+	req.Empty(postgres.GetVersion())
+	req.False(postgres.GetWait())
+	req.Equal(5*time.Minute, postgres.GetTimeout())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"    repo: https://charts.bitnami.com/bitnami\n", "", "helm service redis must have a repo"},
+		{"    chart: oci", "    repo: https://example.com\n    chart: oci", "helm service postgres refers to an OCI chart"},
+		{"timeout: 10m", "timeout: ten", "timeout ten of helm service redis must be a duration"},
+		{"namespace: cache", "namespace: Cache", "namespace Cache of helm service redis"},
+		{"releaseName: redis-${ENVIRONMENT}", "releaseName: redis_dev", "releaseName redis_dev of helm service redis"},
+		{"- redis.yaml", "- /etc/redis.yaml", "valuesFiles of helm service redis must be relative"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestServiceBuild() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    build:
+      dockerfile: services/api/Dockerfile
+      target: release
+      args:
+        ENV: ${ENVIRONMENT}
+  consumer:
+    type: worker
+    build:
+      context: services/consumer
+  cleanup:
+    type: cron
+    schedule: "0 * * * *"
+    command: [cleanup]
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	builders := cfg.Builders()
+	api := builders["api"].GetBuild()
+	req.Equal(".", api.GetContext())
+	req.Equal("services/api/Dockerfile", api.GetDockerfile())
+	req.Equal("release", api.Target)
+	req.Equal(map[string]string{"ENV": "dev"}, api.Args)
+	consumer := builders["consumer"].GetBuild()
+	req.Equal("services/consumer", consumer.GetContext())
+	req.Equal("services/consumer/Dockerfile", consumer.GetDockerfile())
+	req.Nil(builders["cleanup"].GetBuild())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"    build:\n      context", "    image: busybox\n    build:\n      context", "service consumer must have either an image or a build"},
+		{"context: services/consumer", "context: /services/consumer", "build context and dockerfile of service consumer must be relative"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestCronSchedules() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  report:
+    type: cron
+    image: busybox
+    schedule: "30 9 * * MON-FRI"
+    timeZone: America/New_York
+    startingDeadlineSeconds: 0
+    successfulJobsHistoryLimit: 1
+    failedJobsHistoryLimit: 5
+  cleanup:
+    type: cron
+    image: busybox
+    schedule: "@daily"
+    suspend: true
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	crons := lo.KeyBy(cfg.Cronjobs(), func(c Cron) string { return c.GetName() })
+	report := crons["report"]
+	req.Equal("America/New_York", report.GetTimeZone())
+	req.False(report.IsSuspended())
+	req.Equal(lo.ToPtr(0), report.GetStartingDeadlineSeconds())
+	req.Equal(lo.ToPtr(1), report.GetSuccessfulJobsHistoryLimit())
+	req.Equal(lo.ToPtr(5), report.GetFailedJobsHistoryLimit())
+	cleanup := crons["cleanup"]
+	req.True(cleanup.IsSuspended())
+	req.Nil(cleanup.GetStartingDeadlineSeconds())
+	req.Nil(cleanup.GetSuccessfulJobsHistoryLimit())
+
+	// Friday 2023-06-02 10:00 in New York
+	runs, err := NextRuns(report, time.Date(2023, 6, 2, 14, 0, 0, 0, time.UTC), 2)
+	req.NoError(err)
+	loc, err := time.LoadLocation("America/New_York")
+	req.NoError(err)
+	req.Equal([]time.Time{
+		time.Date(2023, 6, 5, 9, 30, 0, 0, loc),
+		time.Date(2023, 6, 6, 9, 30, 0, 0, loc),
+	}, runs)
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{`"30 9 * * MON-FRI"`, `"30 9 * *"`, `schedule "30 9 * *" of cronjob report is not valid`},
+		{`"30 9 * * MON-FRI"`, `"60 9 * * *"`, `schedule "60 9 * * *" of cronjob report is not valid`},
+		{`"30 9 * * MON-FRI"`, `"TZ=UTC 30 9 * * *"`, "time zones are not supported in the schedule"},
+		{"timeZone: America/New_York", "timeZone: Mars/Olympus", "timeZone Mars/Olympus of cronjob report is not a known time zone"},
+		{"failedJobsHistoryLimit: 5", "failedJobsHistoryLimit: -1", "failedJobsHistoryLimit of cronjob report must not be negative"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestScheduling() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+scheduling:
+  nodeSelector:
+    kubernetes.io/arch: arm64
+  tolerations:
+    - key: dedicated
+      value: launchpad
+      effect: NoSchedule
+services:
+  api:
+    type: web
+    scheduling:
+      nodeSelector:
+        node.example.com/pool: web
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+            - weight: 100
+              podAffinityTerm:
+                topologyKey: kubernetes.io/hostname
+      topologySpread:
+        - topologyKey: topology.kubernetes.io/zone
+  consumer:
+    type: worker
+    scheduling:
+      prod:
+        spot: true
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	builders := cfg.Builders()
+	api := builders["api"].GetScheduling()
+	req.Equal(map[string]string{
+		"kubernetes.io/arch":    "arm64",
+		"node.example.com/pool": "web",
+	}, api.GetNodeSelector())
+	req.Equal([]Toleration{{Key: "dedicated", Value: "launchpad", Effect: "NoSchedule"}}, api.GetTolerations())
+	req.Contains(api.Affinity, "podAntiAffinity")
+	req.Equal(1, api.TopologySpread[0].GetMaxSkew())
+	req.Equal("DoNotSchedule", api.TopologySpread[0].GetWhenUnsatisfiable())
+	req.False(builders["consumer"].GetScheduling().IsSpot())
+
+	prodCfg := &Config{selectedEnvironment: "prod"}
+	req.NoError(prodCfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	consumer := prodCfg.Builders()["consumer"].GetScheduling()
+	req.True(consumer.IsSpot())
+	req.Equal(map[string]string{
+		"kubernetes.io/arch":             "arm64",
+		"eks.amazonaws.com/capacityType": "SPOT",
+	}, consumer.GetNodeSelector())
+	req.Equal([]Toleration{
+		{Key: "dedicated", Value: "launchpad", Effect: "NoSchedule"},
+		{Key: "eks.amazonaws.com/capacityType", Operator: "Equal", Value: "SPOT", Effect: "NoSchedule"},
+	}, consumer.GetTolerations())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"kubernetes.io/arch: arm64", "kubernetes.io/arch: arm 64", "nodeSelector kubernetes.io/arch: arm 64 of the project is not a valid node label"},
+		{"    - key: dedicated\n", "    - operator: Equal\n", "tolerations of the project without a key must have operator Exists"},
+		{"      value: launchpad\n", "      value: launchpad\n      operator: Exists\n", "toleration dedicated of the project has operator Exists and must not have a value"},
+		{"effect: NoSchedule", "effect: NoRun", "effect NoRun of toleration dedicated of the project should be one of"},
+		{"effect: NoSchedule", "effect: NoSchedule\n      tolerationSeconds: 60", "toleration dedicated of the project can only have tolerationSeconds with effect NoExecute"},
+		{"preferredDuringScheduling", "preferedDuringScheduling", "affinity of service api is not valid"},
+		{"- topologyKey: topology.kubernetes.io/zone", "- maxSkew: 2", "topologySpread of service api must have a topologyKey"},
+		{"- topologyKey: topology.kubernetes.io/zone", "- topologyKey: topology.kubernetes.io/zone\n          whenUnsatisfiable: Never", "whenUnsatisfiable Never of topologySpread of service api should be one of"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestLabelsAndAnnotations() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+labels:
+  team: payments
+  cost-center: cc-1234
+  environment: ${ENVIRONMENT}
+annotations:
+  example.com/owner: payments@example.com
+services:
+  api:
+    type: web
+    labels:
+      team: checkout
+      example.com/tier: frontend
+    annotations:
+      example.com/runbook: https://wiki.example.com/api
+  cleanup:
+    type: cron
+    schedule: "@daily"
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	req.Equal(map[string]string{
+		"team":        "payments",
+		"cost-center": "cc-1234",
+		"environment": "dev",
+	}, cfg.GetLabels())
+	builders := cfg.Builders()
+	req.Equal(map[string]string{
+		"team":             "checkout",
+		"cost-center":      "cc-1234",
+		"environment":      "dev",
+		"example.com/tier": "frontend",
+	}, builders["api"].GetLabels())
+	req.Equal(map[string]string{
+		"example.com/owner":   "payments@example.com",
+		"example.com/runbook": "https://wiki.example.com/api",
+	}, builders["api"].GetAnnotations())
+	req.Equal(cfg.GetLabels(), builders["cleanup"].GetLabels())
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"cost-center: cc-1234", "cost center: cc-1234", "label cost center of the project must be a name of at most 63 characters"},
+		{"team: checkout", "team: check out", `value "check out" of label team of service api must be at most 63 characters`},
+		{"team: checkout", "jetpack.io/team: checkout", "label jetpack.io/team of service api is reserved by launchpad"},
+		{"team: checkout", "app.kubernetes.io/instance: checkout", "label app.kubernetes.io/instance of service api is reserved by launchpad"},
+		{"example.com/runbook:", "example.com/run/book:", "annotations of service api must have keys of at most 63 characters"},
+		{"environment: ${ENVIRONMENT}", "environment: ${env.UNSET_ENVIRONMENT}", "label or annotation environment"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		req.ErrorContains(cfg.validate(), tc.err)
+	}
+}
+
+func (s *Suite) TestDomains() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+ingress:
+  className: nginx
+  acmeEmail: ops@example.com
+services:
+  web:
+    type: web
+    domains: [example.com, www.example.com]
+  api:
+    type: web
+    domains:
+      dev: ["api.${ENVIRONMENT}.example.com"]
+  admin:
+    type: web
+`
+	req.Empty(validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev"))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	webs := cfg.WebServices()
+	req.Equal([]string{"example.com", "www.example.com"}, webs[0].GetDomains())
+	req.Equal([]string{"api.dev.example.com"}, webs[1].GetDomains())
+	req.Empty(webs[2].GetDomains())
+	req.Equal(IngressFields{ClassName: "nginx", ACMEEmail: "ops@example.com"}, cfg.Ingress)
+
+	for _, tc := range []struct {
+		old, new, err string
+	}{
+		{"www.example.com", "Example.com", "domain Example.com of service web must be a domain name"},
+		{"www.example.com", "localhost", "domain localhost of service web must be a domain name"},
+		{"www.example.com", `"*.example.com"`, "domain *.example.com of service web can't be a wildcard"},
+		{"api.${ENVIRONMENT}", "www", "domain www.example.com is used by services web and api"},
+		{"api.${ENVIRONMENT}", "api.${env.UNSET_ENVIRONMENT}", "Service api domains[0]"},
+		{"  acmeEmail: ops@example.com\n", "", "ingress.acmeEmail must be set"},
+		{"  acmeEmail: ops@example.com\n", "  clusterIssuer: letsencrypt\n", ""},
+		{"acmeEmail: ops@example.com", "acmeEmail: ops", "ingress.acmeEmail ops is not a valid email address"},
+		{"className: nginx", "controller: traefik", "ingress controller traefik should be one of ingress, ambassador"},
+	} {
+		cfg := &Config{selectedEnvironment: "dev"}
+		req.NoError(cfg.loadConfigFromYamlContents(
+			[]byte(strings.Replace(yamlContents, tc.old, tc.new, 1)),
+		))
+		if tc.err == "" {
+			req.NoError(cfg.validate())
+		} else {
+			req.ErrorContains(cfg.validate(), tc.err)
+		}
+	}
+}
+
+func (s *Suite) TestInternalServices() {
+	req := s.Require()
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+services:
+  users:
+    type: internal
+    image: users:latest
+    port: 9000
+`
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+
+	internals := cfg.InternalServices()
+	req.Len(internals, 1)
+	req.Empty(cfg.WebServices())
+	req.Equal("users", internals[0].GetName())
+	req.Equal("users:latest", internals[0].GetImage())
+	req.Equal(9000, internals[0].GetPort())
+	req.Contains(GetServiceTypes(), InternalType)
+
+	// Internal services and workers are deployments too
+	hasDepl, err := cfg.HasDeployment()
+	req.NoError(err)
+	req.True(hasDepl)
+	workerCfg := &Config{}
+	hasDepl, err = workerCfg.HasDeployment()
+	req.NoError(err)
+	req.False(hasDepl)
+	workerCfg.AddNewWorkerService("consumer", []string{"consume"})
+	hasDepl, err = workerCfg.HasDeployment()
+	req.NoError(err)
+	req.True(hasDepl)
+}
+
+func (s *Suite) TestUpgrade() {
+	req := s.Require()
+	oldVersions, oldMigrations := Versions, migrations
+	defer func() { Versions, migrations = oldVersions, oldMigrations }()
+
+	Versions = version{min: "0.1.0", prod: "0.3.0", dev: "0.3.0"}
+	migrations = []migration{
+		{
+			version:     "0.1.1",
+			description: "already applied",
+			migrate:     func(root *yaml.Node) error { return errors.New("unexpected") },
+		},
+		{
+			version:     "0.2.0",
+			description: "rename repo to imageRepository",
+			migrate: func(root *yaml.Node) error {
+				for i := 0; i < len(root.Content); i += 2 {
+					if root.Content[i].Value == "repo" {
+						root.Content[i].Value = "imageRepository"
+					}
+				}
+				return nil
+			},
+		},
+		{
+			version:     "0.3.0",
+			description: "noop",
+			migrate:     func(root *yaml.Node) error { return nil },
+		},
+	}
+
+	contents := `# my project
+configVersion: 0.1.1
+name: py-dockerfile # keep me
+repo: gcr.io/my-project
+`
+	u, err := planUpgrade("launchpad.yaml", []byte(contents))
+	req.NoError(err)
+	req.True(u.NeedsUpgrade())
+	req.Equal("0.1.1", u.FromVersion)
+	req.Equal("0.3.0", u.ToVersion)
+	req.Equal([]string{"rename repo to imageRepository", "noop"}, u.Migrations)
+	req.Equal(`# my project
+configVersion: 0.3.0
+name: py-dockerfile # keep me
+imageRepository: gcr.io/my-project
+`, string(u.Contents()))
+
+	diff, err := u.Diff()
+	req.NoError(err)
+	req.Contains(diff, "--- a/launchpad.yaml\n+++ b/launchpad.yaml\n")
+	req.Contains(diff, "-configVersion: 0.1.1\n+configVersion: 0.3.0\n")
+	req.Contains(diff, "-repo: gcr.io/my-project\n+imageRepository: gcr.io/my-project\n")
+
+	u, err = planUpgrade("launchpad.yaml", []byte(u.Contents()))
+	req.NoError(err)
+	req.False(u.NeedsUpgrade())
+
+	_, err = planUpgrade("launchpad.yaml", []byte("configVersion: 0.0.9\n"))
+	req.ErrorContains(err, "too old to auto-upgrade")
+}
+
+func (s *Suite) TestSaveConfigKeepsComments() {
+	req := s.Require()
+	dir := s.T().TempDir()
+	contents := `# My project
+configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+
+services:
+  # The public API
+  api:
+    type: web
+    port: 8080 # keep in sync with the Dockerfile
+  date-printer-cron:
+    type: cron
+    command: [date]
+    schedule: "* * * * *"
+`
+	req.NoError(os.WriteFile(filepath.Join(dir, "launchpad.yaml"), []byte(contents), 0666))
+
+	cfg, err := RequireBaseFromFileSystem(context.Background(), dir, "dev")
+	req.NoError(err)
+	cfg.Envsec.Provider = JetpackEnvsecProvider
+	req.NoError(cfg.SetField("services.api.port", "9000"))
+	req.NoError(cfg.SetField("services.date-printer-cron.schedule", "0 * * * *"))
+	_, err = cfg.SaveConfig(dir)
+	req.NoError(err)
+
+	saved, err := os.ReadFile(filepath.Join(dir, "launchpad.yaml"))
+	req.NoError(err)
+	req.Equal(`# My project
+configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+
+services:
+  # The public API
+  api:
+    type: web
+    port: 9000 # keep in sync with the Dockerfile
+  date-printer-cron:
+    type: cron
+    command: [date]
+    schedule: "0 * * * *"
+envsec:
+  provider: jetpack
+`, string(saved))
+
+	// saving again without changes keeps the file as is
+	_, err = cfg.SaveConfig(dir)
+	req.NoError(err)
+	resaved, err := os.ReadFile(filepath.Join(dir, "launchpad.yaml"))
+	req.NoError(err)
+	req.Equal(string(saved), string(resaved))
+}
+
+func (s *Suite) TestGetSetField() {
+	req := s.Require()
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents(
+		[]byte("cluster: my-cluster\n" + jetconfigYaml_multi_web),
+	))
+
+	v, err := cfg.GetField("services.admin.port")
+	req.NoError(err)
+	req.Equal("3000", v)
+	v, err = cfg.GetField("services.api")
+	req.NoError(err)
+	req.Equal("type: web\ninstance: small", v)
+	_, err = cfg.GetField("services.admin.url.0")
+	req.ErrorContains(err, "services.admin.url.0 is not set")
+
+	req.NoError(cfg.SetField("environment.prod.namespace", `"{{.Project}}-prod"`))
+	req.Equal("{{.Project}}-prod", cfg.Environment["prod"].Namespace)
+	req.NoError(cfg.SetField("services.admin.port", "4000"))
+	req.Equal(4000, cfg.WebServices()[1].GetPort())
+
+	err = cfg.SetField("services.admin.prot", "4000")
+	req.ErrorContains(err, `unknown field "prot". Did you mean "port"?`)
+	err = cfg.SetField("services.admin.port", "many")
+	req.ErrorContains(err, "cannot set services.admin.port")
+}
+
+func (s *Suite) TestInterpolation() {
+	req := s.Require()
+	s.T().Setenv("IMAGE_TAG", "v1.2.3")
+	s.T().Setenv("EMPTY", "")
+	s.T().Setenv("GIT_BRANCH", "main")
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+name: py-dockerfile
+cluster: my-cluster
+services:
+  api:
+    type: web
+    image: gcr.io/my-project/api:${env.IMAGE_TAG}
+    buildCommand: make build ENV=${ENVIRONMENT} REGISTRY=${env.REGISTRY:-local}
+    url:
+      staging: ${ENVIRONMENT}.example.com
+  cleanup-cron:
+    type: cron
+    schedule: "* * * * *"
+    command: [sh, -c, "echo ${PROJECT_ID} ${env.EMPTY:-none} $${GIT_SHA} ${PORT}"]
+`
+	cfg := &Config{selectedEnvironment: "staging"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(yamlContents)))
+	req.NoError(cfg.validate())
+
+	websvc := cfg.WebServices()[0]
+	req.Equal("gcr.io/my-project/api:v1.2.3", websvc.GetImage())
+	req.Equal("make build ENV=staging REGISTRY=local", websvc.GetBuildCommand())
+	u, err := websvc.GetURL()
+	req.NoError(err)
+	req.Equal("staging.example.com", u.Host)
+	req.Equal(
+		[]string{"sh", "-c", "echo proj_4pss8bskaTPOWzuhyY7cfL none ${GIT_SHA} ${PORT}"},
+		cfg.Cronjobs()[0].GetCommand(),
+	)
+
+	// Only launchpad's variables and env.* are expanded, so that shell
+	// variables in commands keep working
+	v, err := cfg.Interpolate("${IMAGE_TAG} ${env.IMAGE_TAG}")
+	req.NoError(err)
+	req.Equal("${IMAGE_TAG} v1.2.3", v)
+
+	// GIT_BRANCH falls back to the environment outside of a git repository
+	v, err = cfg.Interpolate("${GIT_BRANCH}")
+	req.NoError(err)
+	req.NotEmpty(v)
+
+	cfg = &Config{selectedEnvironment: "staging"}
+	req.NoError(cfg.loadConfigFromYamlContents([]byte(
+		strings.Replace(yamlContents, "${env.IMAGE_TAG}", "${env.MISSING_TAG}", 1),
+	)))
+	err = cfg.validate()
+	req.ErrorContains(err, "Service api image: unresolved variable(s) env.MISSING_TAG")
+}
+
+func (s *Suite) TestUpgradeLegacyConfig() {
+	req := s.Require()
+	contents := `configVersion: "1.0"
+project-id: proj_4pss8bskaTPOWzuhyY7cfL
+# The project
+application:
+  name: py-dockerfile
+kubernetes:
+  context: my-cluster
+image:
+  repository: gcr.io/my-project
+services:
+  api:
+    type: web
+`
+	u, err := planUpgrade("launchpad.yaml", []byte(contents))
+	req.NoError(err)
+	req.Equal("1.0", u.FromVersion)
+	req.Equal(Versions.Prod(), u.ToVersion)
+	req.Len(u.Migrations, 2)
+	req.Equal(`configVersion: "0.1.2"
+projectId: proj_4pss8bskaTPOWzuhyY7cfL
+# The project
+name: py-dockerfile
+cluster: my-cluster
+imageRepository: gcr.io/my-project
+services:
+  api:
+    type: web
+`, string(u.Contents()))
+
+	cfg := &Config{selectedEnvironment: "dev"}
+	req.NoError(cfg.loadConfigFromYamlContents(u.Contents()))
+	req.NoError(cfg.validate())
+	req.Equal("py-dockerfile", cfg.Name)
+	req.Equal("my-cluster", cfg.Cluster)
+}
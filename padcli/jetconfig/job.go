@@ -1,10 +1,48 @@
 package jetconfig
 
+import (
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
 // Public Job interface
 type Job interface {
 	Builder
 	Service
 	GetCommand() []string
+	GetRunBefore() []string
+	IsPreDeploy() bool
+	GetVolumes() []Volume
+	GetBackoffLimit() *int
+	GetActiveDeadlineSeconds() *int
+	GetParallelism() *int
+	GetCompletions() *int
+	GetTTLSecondsAfterFinished() *int
+}
+
+// JobPhase is when a job runs during a deploy.
+type JobPhase string
+
+const (
+	// JobPhaseDeploy jobs run alongside the project's services. This is the
+	// default.
+	JobPhaseDeploy JobPhase = "deploy"
+	// JobPhasePreDeploy jobs run to completion before any service is updated,
+	// e.g. database migrations. If one fails, the deploy is aborted.
+	JobPhasePreDeploy JobPhase = "pre-deploy"
+)
+
+// PreDeployReleaseSuffix is appended to the project's release and instance
+// names to name the release that runs pre-deploy jobs.
+const PreDeployReleaseSuffix = "pre-deploy"
+
+func (JobPhase) jsonSchema() *jsonSchema {
+	return &jsonSchema{
+		Type: "string",
+		Enum: []string{string(JobPhaseDeploy), string(JobPhasePreDeploy)},
+	}
 }
 
 // instantiates a new Job service for initcmd
@@ -14,31 +52,88 @@ func (c *Config) AddNewJobService(
 	schedule string,
 ) Job {
 	newJob := &job{
-		Command: command,
+		Command: newEnvDependentField(command),
 		builder: builder{
-			Image: "busybox:latest",
+			Image: newEnvDependentField("busybox:latest"),
 		},
 		service: service{
-			parent: c,
-			name:   name,
-			Type:   JobType,
+			name: name,
+			Type: JobType,
 		},
 	}
+	newJob.setParent(c)
 	c.Services = append(c.Services, newJob)
 	return newJob
 }
 
 // Private job struct
 type job struct {
-	service `yaml:",inline,omitempty"`
-	builder `yaml:",inline,omitempty"`
-	Command []string `yaml:"command,omitempty,flow"`
+	service       `yaml:",inline,omitempty"`
+	builder       `yaml:",inline,omitempty"`
+	volumeMounter `yaml:",inline,omitempty"`
+	Command       envDependentField[[]string] `yaml:"command,omitempty,flow"`
+	Phase         JobPhase                    `yaml:"phase,omitempty"`
+	// RunBefore are the services that the job must finish before. It implies
+	// the pre-deploy phase.
+	RunBefore []string `yaml:"runBefore,omitempty,flow"`
+	// Unset fields below use the kubernetes defaults, except
+	// ttlSecondsAfterFinished, which defaults to 10 minutes in dev and 24 hours
+	// otherwise.
+	BackoffLimit            envDependentField[int] `yaml:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds   envDependentField[int] `yaml:"activeDeadlineSeconds,omitempty"`
+	Parallelism             envDependentField[int] `yaml:"parallelism,omitempty"`
+	Completions             envDependentField[int] `yaml:"completions,omitempty"`
+	TTLSecondsAfterFinished envDependentField[int] `yaml:"ttlSecondsAfterFinished,omitempty"`
 }
 
 var _ Job = (*job)(nil)
 
+func (c *job) setParent(p *Config) {
+	c.service.setParent(p)
+	c.builder.setParent(p)
+}
+
 func (c *job) GetCommand() []string {
-	return c.Command
+	return lo.Map(c.Command.Get(c.parent.env()), func(arg string, _ int) string {
+		return c.parent.interpolate(arg)
+	})
+}
+
+func (c *job) GetRunBefore() []string {
+	return c.RunBefore
+}
+
+// IsPreDeploy returns true if the job must finish before the project's
+// services are updated. Pre-deploy jobs run before all of them, not only the
+// ones in RunBefore.
+func (c *job) IsPreDeploy() bool {
+	return c.Phase == JobPhasePreDeploy || len(c.RunBefore) > 0
+}
+
+func (c *job) GetBackoffLimit() *int {
+	return c.BackoffLimit.LookupPtr(c.parent.env())
+}
+
+func (c *job) GetActiveDeadlineSeconds() *int {
+	return c.ActiveDeadlineSeconds.LookupPtr(c.parent.env())
+}
+
+func (c *job) GetParallelism() *int {
+	return c.Parallelism.LookupPtr(c.parent.env())
+}
+
+func (c *job) GetCompletions() *int {
+	return c.Completions.LookupPtr(c.parent.env())
+}
+
+func (c *job) GetTTLSecondsAfterFinished() *int {
+	return c.TTLSecondsAfterFinished.LookupPtr(c.parent.env())
+}
+
+func (c *job) interpolatedFields() map[string]string {
+	return lo.Assign(c.builder.interpolatedFields(), map[string]string{
+		"command": strings.Join(c.Command.Get(c.parent.env()), " "),
+	})
 }
 
 func (c *Config) Jobs() []Job {
@@ -50,3 +145,92 @@ func (c *Config) Jobs() []Job {
 	}
 	return result
 }
+
+var nonAlphanumericChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// reservedServiceNamesRule rejects services whose release would have the same
+// name as the release that runs pre-deploy jobs. Release names are lowercase,
+// with '-' in place of any other character.
+func reservedServiceNamesRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		name := strings.ToLower(nonAlphanumericChars.ReplaceAllString(svc.GetName(), "-"))
+		if name == PreDeployReleaseSuffix {
+			return validationError(
+				"service name %s is reserved for the release that runs pre-deploy jobs",
+				svc.GetName(),
+			)
+		}
+	}
+	return nil
+}
+
+func validJobPhaseRule(cfg *Config) error {
+	for _, j := range cfg.Jobs() {
+		phase := j.(*job).Phase
+		if phase != "" && phase != JobPhaseDeploy && phase != JobPhasePreDeploy {
+			return validationError(
+				"phase %s of job %s should be one of %s, %s",
+				phase,
+				j.GetName(),
+				JobPhaseDeploy,
+				JobPhasePreDeploy,
+			)
+		}
+		if phase == JobPhaseDeploy && len(j.GetRunBefore()) > 0 {
+			return validationError(
+				"job %s has runBefore, so its phase must be %s",
+				j.GetName(),
+				JobPhasePreDeploy,
+			)
+		}
+		for _, name := range j.GetRunBefore() {
+			svc, ok := lo.Find(cfg.Services, func(s Service) bool { return s.GetName() == name })
+			if !ok {
+				return validationError(
+					"runBefore of job %s refers to service %s, which does not exist",
+					j.GetName(),
+					name,
+				)
+			}
+			switch svc.(type) {
+			case *web, *internal, *worker:
+			default:
+				return validationError(
+					"runBefore of job %s refers to service %s. "+
+						"runBefore can only refer to web, internal and worker services",
+					j.GetName(),
+					name,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func validJobSpecRule(cfg *Config) error {
+	for _, j := range cfg.Jobs() {
+		for _, f := range []struct {
+			name  string
+			value *int
+			min   int
+		}{
+			{"backoffLimit", j.GetBackoffLimit(), 0},
+			{"activeDeadlineSeconds", j.GetActiveDeadlineSeconds(), 1},
+			// A parallelism of 0 would pause the job, and pre-deploy jobs would
+			// never finish.
+			{"parallelism", j.GetParallelism(), 1},
+			{"completions", j.GetCompletions(), 1},
+			{"ttlSecondsAfterFinished", j.GetTTLSecondsAfterFinished(), 0},
+		} {
+			if f.value != nil && *f.value < f.min {
+				return validationError(
+					"%s of job %s must be at least %d",
+					f.name,
+					j.GetName(),
+					f.min,
+				)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package jetconfig
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/pkg/kubevalidate"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// reservedLabels are set by launchpad to select the pods and resources of a
+// release, so they can't be overridden. Labels starting with jetpack.io/ are
+// reserved too.
+var reservedLabels = []string{
+	"app.kubernetes.io/instance",
+	"app.kubernetes.io/managed-by",
+	"app.kubernetes.io/name",
+}
+
+const reservedLabelPrefix = "jetpack.io/"
+
+// GetLabels returns the labels of every resource of the project, e.g. for
+// cost allocation:
+//
+//	labels:
+//	  team: payments
+//	  cost-center: cc-1234
+//
+// Values support variables.
+func (c *Config) GetLabels() map[string]string {
+	return c.interpolateMap(c.Labels)
+}
+
+// GetAnnotations returns the annotations of every resource of the project.
+func (c *Config) GetAnnotations() map[string]string {
+	return c.interpolateMap(c.Annotations)
+}
+
+func (c *Config) interpolateMap(m map[string]string) map[string]string {
+	return lo.MapValues(m, func(v string, _ string) string {
+		return c.interpolate(v)
+	})
+}
+
+// GetLabels returns the labels of the service's resources: the project's
+// labels merged with its own. It returns nil if there are none.
+func (b *builder) GetLabels() map[string]string {
+	return mergeMaps(b.cfg.GetLabels(), b.cfg.interpolateMap(b.Labels))
+}
+
+// GetAnnotations returns the annotations of the service's resources: the
+// project's annotations merged with its own. It returns nil if there are none.
+func (b *builder) GetAnnotations() map[string]string {
+	return mergeMaps(b.cfg.GetAnnotations(), b.cfg.interpolateMap(b.Annotations))
+}
+
+// mergeMaps is like lo.Assign, but returns nil if the result would be empty.
+func mergeMaps(project, svc map[string]string) map[string]string {
+	if len(project) == 0 && len(svc) == 0 {
+		return nil
+	}
+	return lo.Assign(project, svc)
+}
+
+func (b *builder) labelsInterpolatedFields() map[string]string {
+	fields := map[string]string{}
+	for key, v := range b.Labels {
+		fields["labels."+key] = v
+	}
+	for key, v := range b.Annotations {
+		fields["annotations."+key] = v
+	}
+	return fields
+}
+
+func validLabelsRule(cfg *Config) error {
+	for _, m := range []map[string]string{cfg.Labels, cfg.Annotations} {
+		keys := maps.Keys(m)
+		slices.Sort(keys)
+		for _, key := range keys {
+			if _, err := cfg.Interpolate(m[key]); err != nil {
+				return validationError("label or annotation %s: %v", key, err)
+			}
+		}
+	}
+	if err := validLabels(cfg.GetLabels(), cfg.GetAnnotations(), "the project"); err != nil {
+		return err
+	}
+	for _, svc := range cfg.Services {
+		b, ok := svc.(Builder)
+		if !ok {
+			continue
+		}
+		err := validLabels(b.GetLabels(), b.GetAnnotations(), "service "+svc.GetName())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validLabels(labels, annotations map[string]string, owner string) error {
+	keys := maps.Keys(labels)
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !kubevalidate.IsValidLabelKey(key) {
+			return validationError(
+				"label %s of %s must be a name of at most 63 characters, with an "+
+					"optional prefix such as example.com/",
+				key,
+				owner,
+			)
+		}
+		if slices.Contains(reservedLabels, key) || strings.HasPrefix(key, reservedLabelPrefix) {
+			return validationError("label %s of %s is reserved by launchpad", key, owner)
+		}
+		if !kubevalidate.IsValidLabelValue(labels[key]) {
+			return validationError(
+				"value %q of label %s of %s must be at most 63 characters of letters, "+
+					"numbers, '-', '_' and '.'",
+				labels[key],
+				key,
+				owner,
+			)
+		}
+	}
+	if !kubevalidate.IsValidAnnotations(annotations) {
+		return validationError(
+			"annotations of %s must have keys of at most 63 characters, with an "+
+				"optional prefix such as example.com/, and be at most 256KiB in total",
+			owner,
+		)
+	}
+	return nil
+}
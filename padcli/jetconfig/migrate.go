@@ -0,0 +1,268 @@
+package jetconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"go.jetpack.io/launchpad/padcli/semver"
+	"go.jetpack.io/launchpad/padcli/terminal"
+	"go.jetpack.io/launchpad/pkg/jetlog"
+	"gopkg.in/yaml.v3"
+)
+
+// migration rewrites a config that follows the previous version in the chain
+// so that it follows version. It works on the raw yaml rather than on Config,
+// because older configs may no longer decode into it.
+type migration struct {
+	version     string // the configVersion after this migration
+	description string
+	migrate     func(root *yaml.Node) error
+}
+
+// migrations is the ordered chain of schema changes (see docs.go). When the
+// schema changes in a way that existing configs need to be rewritten, append a
+// migration to the new version and bump Versions. A config that skipped
+// several releases goes through every migration after its configVersion, in
+// order.
+var migrations = []migration{
+	{
+		version: "0.1.1",
+		description: "Moved application.name to name, kubernetes.context to cluster " +
+			"and image.repository to imageRepository",
+		migrate: func(root *yaml.Node) error {
+			moveYamlKey(root, []string{"application", "name"}, "name")
+			moveYamlKey(root, []string{"kubernetes", "context"}, "cluster")
+			moveYamlKey(root, []string{"image", "repository"}, "imageRepository")
+			return nil
+		},
+	},
+	{
+		version:     "0.1.2",
+		description: "Renamed project-id to projectId",
+		migrate: func(root *yaml.Node) error {
+			if key := mappingKey(root, "project-id"); key != nil {
+				key.Value = "projectId"
+			}
+			return nil
+		},
+	},
+}
+
+// Upgrade is a config file upgraded to the latest version. Nothing is written
+// until Write is called.
+type Upgrade struct {
+	Path        string
+	FromVersion string
+	ToVersion   string
+	// Migrations are the descriptions of the migrations applied, in order.
+	Migrations []string
+
+	before []byte
+	after  []byte
+}
+
+// PlanUpgrade upgrades the config at path in memory.
+func PlanUpgrade(path string) (*Upgrade, error) {
+	filePath := configPath(path)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, ErrConfigNotFound
+	}
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read jetconfig file at %s", filePath)
+	}
+	return planUpgrade(filePath, contents)
+}
+
+func planUpgrade(filePath string, contents []byte) (*Upgrade, error) {
+	u := &Upgrade{Path: filePath, before: contents, after: contents}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to read jetconfig. yaml file is invalid")
+	}
+	if len(doc.Content) == 0 {
+		// empty file
+		return u, nil
+	}
+	root := doc.Content[0]
+	versionNode := findNode(root, []string{"configVersion"})
+	if versionNode == nil || versionNode.Kind != yaml.ScalarNode {
+		// Nothing to upgrade from. Validation reports the missing version.
+		return u, nil
+	}
+	u.FromVersion = versionNode.Value
+	u.ToVersion = versionNode.Value
+
+	configFileName := ConfigName(filePath)
+	if isUnsupported, err := isVersionLessThanMinimumSupported(u.FromVersion); err != nil {
+		return nil, errors.WithStack(err)
+	} else if isUnsupported {
+		return nil, errorutil.NewUserErrorf(
+			"The configVersion in %s is too old to auto-upgrade. "+
+				"Please reach out for support, or consult docs at "+
+				"https://www.jetpack.io/launchpad/docs/reference/launchpad.yaml-reference/",
+			configFileName,
+		)
+	}
+	if needsUpgrade, err := doesVersionNeedUpgrade(u.FromVersion); err != nil {
+		return nil, errors.WithStack(err)
+	} else if !needsUpgrade {
+		return u, nil
+	}
+
+	for _, m := range migrations {
+		if after, err := semver.Compare(m.version, semverOf(u.FromVersion)); err != nil {
+			return nil, errors.WithStack(err)
+		} else if after <= 0 {
+			continue
+		}
+		if cmp, err := semver.Compare(m.version, Versions.Prod()); err != nil {
+			return nil, errors.WithStack(err)
+		} else if cmp > 0 {
+			break
+		}
+		if err := m.migrate(root); err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"failed to upgrade %s to version %s",
+				configFileName,
+				m.version,
+			)
+		}
+		versionNode.Value = m.version
+		u.Migrations = append(u.Migrations, m.description)
+	}
+	versionNode.Value = Versions.Prod()
+	u.ToVersion = Versions.Prod()
+
+	// Encoding the node rather than the Config keeps comments and key order.
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	u.after = restoreBlankLines(contents, buf.Bytes())
+	return u, nil
+}
+
+// NeedsUpgrade returns true if the config is older than the latest version.
+func (u *Upgrade) NeedsUpgrade() bool {
+	return u.FromVersion != u.ToVersion
+}
+
+// Contents returns the upgraded config file.
+func (u *Upgrade) Contents() []byte {
+	return u.after
+}
+
+// Diff returns a unified diff from the current config file to the upgraded
+// one.
+func (u *Upgrade) Diff() (string, error) {
+	name := filepath.Base(u.Path)
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(u.before)),
+		B:        difflib.SplitLines(string(u.after)),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+	return diff, errors.WithStack(err)
+}
+
+// Write saves the upgraded config file.
+func (u *Upgrade) Write() error {
+	return errors.Wrapf(
+		os.WriteFile(u.Path, u.after, 0666),
+		"failed to write %s",
+		u.Path,
+	)
+}
+
+// upgrade the config file contents to follow the latest schema, and save them.
+//
+// This happens before the contents are loaded into a Config, because older
+// configs may not decode, and before overlays are applied, so that only the
+// base config is saved. Configs older than Versions.MinSupported() are not
+// upgraded: users are expected to auto-upgrade because they regularly deploy
+// jetpack projects, or reach out for support if they need to manually update a
+// very old project.
+func upgrade(ctx context.Context, filePath string, contents []byte) ([]byte, error) {
+	u, err := planUpgrade(filePath, contents)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !u.NeedsUpgrade() {
+		return contents, nil
+	}
+
+	configFileName := ConfigName(filePath)
+	// stop the upgrade if we are in a non-interactive terminal.
+	if !terminal.IsInteractive() {
+		return nil, errorutil.NewUserErrorf(
+			"The configVersion in %s needs an upgrade. Please run `launchpad config upgrade`.",
+			configFileName,
+		)
+	}
+
+	if err := u.Write(); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to auto-upgrade %s", configFileName))
+	}
+	jetlog.Logger(ctx).WarningPrintf(
+		"Upgraded your %s to the latest version %s. "+
+			"Please commit this change to your repository.%s",
+		configFileName,
+		u.ToVersion,
+		strings.Join(
+			append([]string{""}, u.Migrations...),
+			"\n  - ",
+		),
+	)
+	return u.Contents(), nil
+}
+
+// moveYamlKey moves the value at the path from (a key nested in a top level
+// mapping) to the top level key to. The top level mapping is removed if it
+// becomes empty.
+func moveYamlKey(root *yaml.Node, from []string, to string) {
+	parent := findNode(root, from[:len(from)-1])
+	value := mappingValue(parent, from[len(from)-1])
+	if value == nil || mappingValue(root, to) != nil {
+		return
+	}
+	*parent = *withoutKey(parent, from[len(from)-1])
+
+	parentKey := mappingKey(root, from[0])
+	idx := lo.IndexOf(root.Content, parentKey)
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: to}
+	if len(parent.Content) == 0 {
+		key.HeadComment = parentKey.HeadComment
+		root.Content[idx], root.Content[idx+1] = key, value
+		return
+	}
+	root.Content = append(
+		root.Content[:idx],
+		append([]*yaml.Node{key, value}, root.Content[idx:]...)...,
+	)
+}
+
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for _, pair := range lo.Chunk(node.Content, 2) {
+		if pair[0].Value == key {
+			return pair[0]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package jetconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// overlayPath returns the path of the overlay file for env that sits next to
+// the config at filePath. For example launchpad.yaml -> launchpad.prod.yaml
+func overlayPath(filePath string, env string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + env + ext
+}
+
+// OverlayPath returns the path of the environment overlay that was merged into
+// this config, or an empty string if there was none.
+func (cfg *Config) OverlayPath() string {
+	return cfg.overlayPath
+}
+
+// applyOverlay deep-merges the overlay file for the selected environment (if
+// any) over the config.
+func (cfg *Config) applyOverlay() error {
+	if cfg.selectedEnvironment == "" {
+		return nil
+	}
+	overlayFile := overlayPath(cfg.Path, cfg.selectedEnvironment)
+	overlayContents, err := os.ReadFile(overlayFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to read %s", overlayFile)
+	}
+
+	overlay := &yaml.Node{}
+	if err := yaml.Unmarshal(overlayContents, overlay); err != nil {
+		return errors.Wrapf(err, "failed to read %s. yaml file is invalid", overlayFile)
+	}
+	if len(overlay.Content) == 0 {
+		// empty file
+		return nil
+	}
+
+	base := &yaml.Node{}
+	if err := base.Encode(cfg); err != nil {
+		return errors.WithStack(err)
+	}
+	merged := mergeYamlNodes(base, overlay.Content[0])
+	if err := cfg.decodeYamlNode(merged); err != nil {
+		return errors.Wrapf(err, "failed to apply %s", filepath.Base(overlayFile))
+	}
+	if !cfg.AllowUnknownFields {
+		// The base is encoded from the decoded config, so any unknown field in
+		// the merged node comes from the overlay and keeps its line and column.
+		// The overlay can't be checked on its own because its services may omit
+		// their type.
+		err := unknownFieldsError(merged, filepath.Base(overlayFile))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	cfg.overlayPath = overlayFile
+	return nil
+}
+
+// mergeYamlNodes deep-merges overlay into base. Mappings are merged key by key,
+// which means services are merged by name. Any other overlay value (scalars,
+// sequences) replaces the base value.
+func mergeYamlNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base.Kind == yaml.DocumentNode && len(base.Content) > 0 {
+		base = base.Content[0]
+	}
+	if overlay.Kind != yaml.MappingNode || base.Kind != yaml.MappingNode {
+		return overlay
+	}
+
+	merged := *base
+	merged.Content = append([]*yaml.Node{}, base.Content...)
+	for _, pair := range lo.Chunk(overlay.Content, 2) {
+		if len(pair) != 2 {
+			continue
+		}
+		idx := lo.IndexOf(
+			lo.Map(lo.Chunk(merged.Content, 2), func(p []*yaml.Node, _ int) string {
+				return p[0].Value
+			}),
+			pair[0].Value,
+		)
+		if idx < 0 {
+			merged.Content = append(merged.Content, pair...)
+			continue
+		}
+		merged.Content[2*idx+1] = mergeYamlNodes(merged.Content[2*idx+1], pair[1])
+	}
+	return &merged
+}
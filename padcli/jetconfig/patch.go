@@ -0,0 +1,301 @@
+package jetconfig
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
+	"gopkg.in/yaml.v3"
+)
+
+// patchedYaml returns the config as yaml. If the config was loaded from a
+// file, only the nodes of the original document whose values changed since it
+// was loaded are patched, so that comments, blank lines and key order are kept.
+func (cfg *Config) patchedYaml() ([]byte, error) {
+	if cfg.doc == nil {
+		return cfg.marshalYaml()
+	}
+
+	values := &yaml.Node{}
+	if err := values.Encode(cfg); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	doc := *cfg.doc
+	doc.Content = []*yaml.Node{patchYamlNode(cfg.doc.Content[0], cfg.docValues, values)}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to yaml marshal jetconfig: %v", cfg)
+	}
+	cfg.doc = &doc
+	cfg.docValues = values
+	return restoreBlankLines(cfg.docContents, buf.Bytes()), nil
+}
+
+// setDoc records the document that the config was decoded from, along with
+// the config's own encoding of it to compare against when saving.
+func (cfg *Config) setDoc(doc *yaml.Node, contents []byte) error {
+	values := &yaml.Node{}
+	if err := values.Encode(cfg); err != nil {
+		return errors.WithStack(err)
+	}
+	cfg.doc = doc
+	cfg.docValues = values
+	cfg.docContents = contents
+	return nil
+}
+
+// restoreBlankLines adds back the blank lines of orig that yaml.v3 drops when
+// encoding. Blank lines are restored before the line that followed them in
+// orig, if out still has that line.
+func restoreBlankLines(orig, out []byte) []byte {
+	origLines, origBlanks := nonBlankLines(orig)
+	outLines, outBlanks := nonBlankLines(out)
+
+	matcher := difflib.NewMatcherWithJunk(origLines, outLines, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag != 'e' && op.Tag != 'r' {
+			continue
+		}
+		for n := 0; op.I1+n < op.I2 && op.J1+n < op.J2; n++ {
+			// blank lines in block scalars are already in out
+			outBlanks[op.J1+n] = lo.Max([]int{outBlanks[op.J1+n], origBlanks[op.I1+n]})
+		}
+	}
+
+	result := []string{}
+	for i, line := range outLines {
+		result = append(result, make([]string, outBlanks[i])...)
+		result = append(result, line)
+	}
+	return []byte(strings.Join(result, "\n") + "\n")
+}
+
+// nonBlankLines returns the lines of contents that are not blank, and the
+// number of blank lines before each of them.
+func nonBlankLines(contents []byte) ([]string, []int) {
+	lines := []string{}
+	blanks := []int{}
+	n := 0
+	for _, line := range strings.Split(strings.TrimRight(string(contents), "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			n++
+			continue
+		}
+		lines = append(lines, line)
+		blanks = append(blanks, n)
+		n = 0
+	}
+	return lines, blanks
+}
+
+// patchYamlNode applies the changes from before to after onto orig, which is
+// the node that before was decoded from. Parts of orig that didn't change are
+// returned as is, which keeps their comments and formatting, and keys that are
+// not part of the config (e.g. with allowUnknownFields) are left alone.
+func patchYamlNode(orig, before, after *yaml.Node) *yaml.Node {
+	if before != nil && yamlNodesEqual(before, after) {
+		return orig
+	}
+	if orig.Kind != after.Kind || before == nil || before.Kind != after.Kind {
+		return replaceYamlNode(orig, after)
+	}
+
+	switch after.Kind {
+	case yaml.MappingNode:
+		patched := *orig
+		patched.Content = append([]*yaml.Node{}, orig.Content...)
+		for _, pair := range lo.Chunk(after.Content, 2) {
+			key := pair[0].Value
+			o := mappingValue(&patched, key)
+			if o == nil {
+				patched.Content = append(patched.Content, pair...)
+				continue
+			}
+			idx := lo.IndexOf(patched.Content, o)
+			patched.Content[idx] = patchYamlNode(o, mappingValue(before, key), pair[1])
+		}
+		for _, pair := range lo.Chunk(before.Content, 2) {
+			if mappingValue(after, pair[0].Value) == nil {
+				patched.Content = withoutKey(&patched, pair[0].Value).Content
+			}
+		}
+		return &patched
+	case yaml.SequenceNode:
+		if len(orig.Content) != len(before.Content) || len(before.Content) != len(after.Content) {
+			return replaceYamlNode(orig, after)
+		}
+		patched := *orig
+		patched.Content = make([]*yaml.Node, len(orig.Content))
+		for i := range orig.Content {
+			patched.Content[i] = patchYamlNode(orig.Content[i], before.Content[i], after.Content[i])
+		}
+		return &patched
+	default:
+		return replaceYamlNode(orig, after)
+	}
+}
+
+// replaceYamlNode returns after, with the comments of orig.
+func replaceYamlNode(orig, after *yaml.Node) *yaml.Node {
+	replaced := *after
+	if orig.Kind == after.Kind && (after.Kind != yaml.ScalarNode || after.Tag == orig.Tag) {
+		// e.g. keep quotes, or lists in flow style
+		replaced.Style = orig.Style
+	}
+	replaced.HeadComment = orig.HeadComment
+	replaced.LineComment = orig.LineComment
+	replaced.FootComment = orig.FootComment
+	return &replaced
+}
+
+func yamlNodesEqual(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode && (a.Value != b.Value || a.ShortTag() != b.ShortTag()) {
+		return false
+	}
+	for i := range a.Content {
+		if !yamlNodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for _, pair := range lo.Chunk(node.Content, 2) {
+		if len(pair) == 2 && pair[0].Value == key {
+			return pair[1]
+		}
+	}
+	return nil
+}
+
+// splitFieldPath splits a dot separated path such as services.api.port
+func splitFieldPath(path string) ([]string, error) {
+	keys := strings.Split(path, ".")
+	if lo.Contains(keys, "") {
+		return nil, errorutil.NewUserErrorf("invalid field path %q", path)
+	}
+	return keys, nil
+}
+
+// GetField returns the value at a dot separated path such as
+// services.api.port. Scalars are returned as is and other values as yaml.
+// Sequence items can be selected by index, e.g. services.api.command.0
+func (cfg *Config) GetField(path string) (string, error) {
+	keys, err := splitFieldPath(path)
+	if err != nil {
+		return "", err
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(cfg); err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, key := range keys {
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = mappingValue(node, key)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				node = nil
+			} else {
+				node = node.Content[i]
+			}
+		default:
+			node = nil
+		}
+		if node == nil {
+			return "", errorutil.NewUserErrorf("%s is not set", path)
+		}
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// SetField sets the value at a dot separated path such as services.api.port,
+// creating any missing parent mappings. The value is parsed as yaml, so 8080
+// is a number and [a, b] is a list. The config is validated but not saved.
+func (cfg *Config) SetField(path string, value string) error {
+	keys, err := splitFieldPath(path)
+	if err != nil {
+		return err
+	}
+	valueDoc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(value), valueDoc); err != nil {
+		return errorutil.NewUserErrorf("invalid value %q: %v", value, err)
+	}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+	if len(valueDoc.Content) > 0 {
+		valueNode = valueDoc.Content[0]
+	}
+
+	values := &yaml.Node{}
+	if err := values.Encode(cfg); err != nil {
+		return errors.WithStack(err)
+	}
+	node := values
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return errorutil.NewUserErrorf(
+				"cannot set %s because %s is not a mapping",
+				path,
+				strings.Join(keys[:i], "."),
+			)
+		}
+		next := mappingValue(node, key)
+		if i == len(keys)-1 {
+			if next != nil {
+				node.Content[lo.IndexOf(node.Content, next)] = valueNode
+			} else {
+				node.Content = append(
+					node.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+					valueNode,
+				)
+			}
+			break
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(
+				node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				next,
+			)
+		}
+		node = next
+	}
+
+	if err := cfg.decodeYamlNode(values); err != nil {
+		return errorutil.NewUserErrorf("cannot set %s: %v", path, errors.Cause(err))
+	}
+	// Unknown fields would be dropped when saving, so they are always errors.
+	for _, p := range validateNode(configSchemaFor(environmentNames(values)), values, nil) {
+		if p.isUnknownField {
+			return errorutil.NewUserErrorf("cannot set %s: %s", path, p.Message)
+		}
+	}
+	return cfg.validate()
+}
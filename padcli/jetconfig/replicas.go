@@ -0,0 +1,110 @@
+package jetconfig
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Replicas is the number of replicas of a web service. A number is a fixed
+// count. A mapping with a max greater than min autoscales between the two,
+// based on the average cpu and/or memory utilization as a percentage of the
+// requests:
+//
+//	replicas: 3
+//
+//	replicas:
+//	  min: 2
+//	  max: 10
+//	  targetCPU: 70
+type Replicas struct {
+	Min          int `yaml:"min,omitempty"`
+	Max          int `yaml:"max,omitempty"`
+	TargetCPU    int `yaml:"targetCPU,omitempty"`
+	TargetMemory int `yaml:"targetMemory,omitempty"`
+}
+
+// defaultTargetCPU is used if an autoscaled service sets no target.
+const defaultTargetCPU = 80
+
+// plainReplicas has the fields of Replicas without its yaml methods.
+type plainReplicas Replicas
+
+func (r *Replicas) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var n int
+		if err := value.Decode(&n); err != nil {
+			return errors.WithStack(err)
+		}
+		*r = Replicas{Min: n}
+		return nil
+	}
+	return errors.WithStack(value.Decode((*plainReplicas)(r)))
+}
+
+func (r Replicas) MarshalYAML() (any, error) {
+	if r == (Replicas{Min: r.Min}) {
+		return r.Min, nil
+	}
+	return plainReplicas(r), nil
+}
+
+func (Replicas) jsonSchema() *jsonSchema {
+	return &jsonSchema{OneOf: []*jsonSchema{
+		{Type: "integer"},
+		schemaForType(reflect.TypeOf(plainReplicas{})),
+	}}
+}
+
+// IsAutoscaled returns true if the service scales between Min and Max.
+func (r *Replicas) IsAutoscaled() bool {
+	return r != nil && r.Max > r.Min
+}
+
+// GetReplicas returns the replicas of the web service for the selected
+// environment, or nil if they are not set. If only max is set, min is 1.
+func (w *web) GetReplicas() *Replicas {
+	r, ok := w.Replicas.Lookup(w.parent.env())
+	if !ok {
+		return nil
+	}
+	if r.Min == 0 && r.Max > 0 {
+		r.Min = 1
+	}
+	if r.IsAutoscaled() && r.TargetCPU == 0 && r.TargetMemory == 0 {
+		r.TargetCPU = defaultTargetCPU
+	}
+	return &r
+}
+
+func validWebReplicasRule(cfg *Config) error {
+	for _, w := range cfg.WebServices() {
+		r := w.GetReplicas()
+		if r == nil {
+			continue
+		}
+		if r.Min < 0 || r.TargetCPU < 0 || r.TargetMemory < 0 {
+			return validationError(
+				"replicas of web service %s must not be negative",
+				w.GetName(),
+			)
+		}
+		if r.Max != 0 && r.Max < r.Min {
+			return validationError(
+				"replicas max %d of web service %s must not be less than min %d",
+				r.Max,
+				w.GetName(),
+				r.Min,
+			)
+		}
+		if !r.IsAutoscaled() && (r.TargetCPU != 0 || r.TargetMemory != 0) {
+			return validationError(
+				"replicas targetCPU and targetMemory of web service %s only apply "+
+					"when max is greater than min",
+				w.GetName(),
+			)
+		}
+	}
+	return nil
+}
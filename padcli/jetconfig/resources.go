@@ -0,0 +1,134 @@
+package jetconfig
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Resources are the CPU and memory requests and limits of a service's
+// containers. They override or extend the instance type:
+//
+//	instance: small
+//	resources:
+//	  requests:
+//	    memory: 3Gi
+//	  limits:
+//	    memory: 4Gi
+type Resources struct {
+	Requests ResourceList `yaml:"requests,omitempty"`
+	Limits   ResourceList `yaml:"limits,omitempty"`
+}
+
+// ResourceList holds Kubernetes quantities such as 500m, 2 or 1Gi.
+type ResourceList struct {
+	CPU    Quantity `yaml:"cpu,omitempty"`
+	Memory Quantity `yaml:"memory,omitempty"`
+}
+
+// Quantity is a Kubernetes resource quantity. Plain numbers are accepted, so
+// that `cpu: 2` works as it does in Kubernetes manifests.
+type Quantity string
+
+func (Quantity) jsonSchema() *jsonSchema {
+	return &jsonSchema{OneOf: []*jsonSchema{{Type: "string"}, {Type: "number"}}}
+}
+
+func (q Quantity) parse() (resource.Quantity, error) {
+	quantity, err := resource.ParseQuantity(string(q))
+	return quantity, errors.WithStack(err)
+}
+
+// Values returns the resources as app chart values. Unset quantities are
+// omitted.
+func (r Resources) Values() map[string]any {
+	values := map[string]any{}
+	if requests := r.Requests.values(); len(requests) > 0 {
+		values["requests"] = requests
+	}
+	if limits := r.Limits.values(); len(limits) > 0 {
+		values["limits"] = limits
+	}
+	return values
+}
+
+func (l ResourceList) values() map[string]any {
+	values := map[string]any{}
+	if l.CPU != "" {
+		values["cpu"] = string(l.CPU)
+	}
+	if l.Memory != "" {
+		values["memory"] = string(l.Memory)
+	}
+	return values
+}
+
+// GetResources returns the resources of the service. Requests that are not
+// set explicitly come from the instance type if there is one, else from the
+// limit (as they would in Kubernetes), else from the default instance type.
+func (b *builder) GetResources() Resources {
+	r := b.Resources.Get(b.cfg.env())
+	it := b.GetInstanceType()
+	if r.Requests.CPU == "" {
+		r.Requests.CPU = Quantity(it.Compute())
+		if it == nil && r.Limits.CPU != "" {
+			r.Requests.CPU = r.Limits.CPU
+		}
+	}
+	if r.Requests.Memory == "" {
+		r.Requests.Memory = Quantity(it.Memory())
+		if it == nil && r.Limits.Memory != "" {
+			r.Requests.Memory = r.Limits.Memory
+		}
+	}
+	return r
+}
+
+func validResourcesRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		b, ok := svc.(Builder)
+		if !ok {
+			continue
+		}
+		r := b.GetResources()
+		for _, q := range []struct {
+			name           string
+			request, limit Quantity
+		}{
+			{"cpu", r.Requests.CPU, r.Limits.CPU},
+			{"memory", r.Requests.Memory, r.Limits.Memory},
+		} {
+			request, err := q.request.parse()
+			if err != nil {
+				return validationError(
+					"Service %s %s request %q is not a valid quantity, e.g. 500m or 1Gi",
+					svc.GetName(),
+					q.name,
+					q.request,
+				)
+			}
+			if q.limit == "" {
+				continue
+			}
+			limit, err := q.limit.parse()
+			if err != nil {
+				return validationError(
+					"Service %s %s limit %q is not a valid quantity, e.g. 500m or 1Gi",
+					svc.GetName(),
+					q.name,
+					q.limit,
+				)
+			}
+			if request.Cmp(limit) > 0 {
+				return validationError(
+					"Service %s %s request %s is greater than its limit %s. "+
+						"Lower the request, or choose a smaller instance type",
+					svc.GetName(),
+					q.name,
+					q.request,
+					q.limit,
+				)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package jetconfig
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Scheduling is where a service's pods run. It can be set for the whole
+// project and per service:
+//
+//	scheduling:
+//	  nodeSelector:
+//	    kubernetes.io/arch: arm64
+//	services:
+//	  api:
+//	    type: web
+//	    scheduling:
+//	      spot: true
+//	      topologySpread:
+//	        - topologyKey: topology.kubernetes.io/zone
+//
+// A service's nodeSelector is merged with the project's. Its other fields
+// replace the project's when set.
+type Scheduling struct {
+	// Spot runs the pods on spot nodes, which are labeled, and optionally
+	// tainted, with eks.amazonaws.com/capacityType=SPOT.
+	Spot         *bool             `yaml:"spot,omitempty"`
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty"`
+	Tolerations  []Toleration      `yaml:"tolerations,omitempty"`
+	// Affinity is a Kubernetes affinity, with nodeAffinity, podAffinity and
+	// podAntiAffinity.
+	Affinity       map[string]any   `yaml:"affinity,omitempty"`
+	TopologySpread []TopologySpread `yaml:"topologySpread,omitempty"`
+}
+
+// Toleration is a Kubernetes toleration.
+type Toleration struct {
+	Key               string `yaml:"key,omitempty"`
+	Operator          string `yaml:"operator,omitempty"`
+	Value             string `yaml:"value,omitempty"`
+	Effect            string `yaml:"effect,omitempty"`
+	TolerationSeconds *int   `yaml:"tolerationSeconds,omitempty"`
+}
+
+// TopologySpread spreads the pods of a service across nodes, zones, etc.
+type TopologySpread struct {
+	// MaxSkew defaults to 1.
+	MaxSkew     int    `yaml:"maxSkew,omitempty"`
+	TopologyKey string `yaml:"topologyKey"`
+	// WhenUnsatisfiable is DoNotSchedule (the default) or ScheduleAnyway.
+	WhenUnsatisfiable string `yaml:"whenUnsatisfiable,omitempty"`
+	// LabelSelector selects the pods to spread. It defaults to the pods of the
+	// service.
+	LabelSelector map[string]string `yaml:"labelSelector,omitempty"`
+}
+
+const (
+	spotNodeLabel = "eks.amazonaws.com/capacityType"
+	spotNodeValue = "SPOT"
+)
+
+// IsSpot returns true if the pods run on spot nodes.
+func (s Scheduling) IsSpot() bool {
+	return lo.FromPtr(s.Spot)
+}
+
+// GetNodeSelector returns the node selector, including the spot node label
+// if the pods run on spot nodes.
+func (s Scheduling) GetNodeSelector() map[string]string {
+	if !s.IsSpot() {
+		return s.NodeSelector
+	}
+	return lo.Assign(s.NodeSelector, map[string]string{spotNodeLabel: spotNodeValue})
+}
+
+// GetTolerations returns the tolerations, including the toleration of the
+// spot node taint if the pods run on spot nodes.
+func (s Scheduling) GetTolerations() []Toleration {
+	if !s.IsSpot() {
+		return s.Tolerations
+	}
+	return append(slices.Clone(s.Tolerations), Toleration{
+		Key:      spotNodeLabel,
+		Operator: string(corev1.TolerationOpEqual),
+		Value:    spotNodeValue,
+		Effect:   string(corev1.TaintEffectNoSchedule),
+	})
+}
+
+// GetMaxSkew returns the max skew, which defaults to 1.
+func (t TopologySpread) GetMaxSkew() int {
+	return lo.Ternary(t.MaxSkew == 0, 1, t.MaxSkew)
+}
+
+// GetWhenUnsatisfiable returns what to do with pods that can't be spread.
+func (t TopologySpread) GetWhenUnsatisfiable() string {
+	if t.WhenUnsatisfiable == "" {
+		return string(corev1.DoNotSchedule)
+	}
+	return t.WhenUnsatisfiable
+}
+
+// mergedWith returns the scheduling of a service, with s as the project's
+// defaults.
+func (s Scheduling) mergedWith(svc Scheduling) Scheduling {
+	merged := s
+	if svc.Spot != nil {
+		merged.Spot = svc.Spot
+	}
+	if len(svc.NodeSelector) > 0 {
+		merged.NodeSelector = lo.Assign(s.NodeSelector, svc.NodeSelector)
+	}
+	if len(svc.Tolerations) > 0 {
+		merged.Tolerations = svc.Tolerations
+	}
+	if len(svc.Affinity) > 0 {
+		merged.Affinity = svc.Affinity
+	}
+	if len(svc.TopologySpread) > 0 {
+		merged.TopologySpread = svc.TopologySpread
+	}
+	return merged
+}
+
+// GetScheduling returns where the service's pods run, merged with the
+// project's defaults.
+func (b *builder) GetScheduling() Scheduling {
+	return b.cfg.Scheduling.Get(b.cfg.env()).mergedWith(b.Scheduling.Get(b.cfg.env()))
+}
+
+func validSchedulingRule(cfg *Config) error {
+	if err := validScheduling(cfg.Scheduling.Get(cfg.env()), "the project"); err != nil {
+		return err
+	}
+	for _, svc := range cfg.Services {
+		if b, ok := svc.(Builder); ok {
+			if err := validScheduling(b.GetScheduling(), "service "+svc.GetName()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validScheduling(s Scheduling, owner string) error {
+	keys := maps.Keys(s.NodeSelector)
+	slices.Sort(keys)
+	for _, key := range keys {
+		if len(validation.IsQualifiedName(key)) > 0 ||
+			len(validation.IsValidLabelValue(s.NodeSelector[key])) > 0 {
+			return validationError(
+				"nodeSelector %s: %s of %s is not a valid node label",
+				key,
+				s.NodeSelector[key],
+				owner,
+			)
+		}
+	}
+
+	for _, t := range s.Tolerations {
+		if err := validToleration(t, owner); err != nil {
+			return err
+		}
+	}
+
+	if len(s.Affinity) > 0 {
+		// Decode into the Kubernetes type to catch misspelled fields, which the
+		// API server would otherwise drop silently.
+		b, err := json.Marshal(s.Affinity)
+		if err == nil {
+			decoder := json.NewDecoder(bytes.NewReader(b))
+			decoder.DisallowUnknownFields()
+			err = decoder.Decode(&corev1.Affinity{})
+		}
+		if err != nil {
+			return validationError("affinity of %s is not valid: %s", owner, err)
+		}
+	}
+
+	for _, t := range s.TopologySpread {
+		if t.TopologyKey == "" || len(validation.IsQualifiedName(t.TopologyKey)) > 0 {
+			return validationError(
+				"topologySpread of %s must have a topologyKey, such as "+
+					"topology.kubernetes.io/zone",
+				owner,
+			)
+		}
+		if t.MaxSkew < 0 {
+			return validationError("maxSkew of topologySpread of %s must be at least 1", owner)
+		}
+		switch corev1.UnsatisfiableConstraintAction(t.GetWhenUnsatisfiable()) {
+		case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+		default:
+			return validationError(
+				"whenUnsatisfiable %s of topologySpread of %s should be one of %s, %s",
+				t.WhenUnsatisfiable,
+				owner,
+				corev1.DoNotSchedule,
+				corev1.ScheduleAnyway,
+			)
+		}
+		keys := maps.Keys(t.LabelSelector)
+		slices.Sort(keys)
+		for _, key := range keys {
+			if len(validation.IsQualifiedName(key)) > 0 ||
+				len(validation.IsValidLabelValue(t.LabelSelector[key])) > 0 {
+				return validationError(
+					"labelSelector %s: %s of topologySpread of %s is not a valid label",
+					key,
+					t.LabelSelector[key],
+					owner,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func validToleration(t Toleration, owner string) error {
+	switch corev1.TolerationOperator(t.Operator) {
+	case "", corev1.TolerationOpEqual:
+		if t.Key == "" {
+			return validationError(
+				"tolerations of %s without a key must have operator Exists",
+				owner,
+			)
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			return validationError(
+				"toleration %s of %s has operator Exists and must not have a value",
+				t.Key,
+				owner,
+			)
+		}
+	default:
+		return validationError(
+			"operator %s of toleration %s of %s should be one of %s, %s",
+			t.Operator,
+			t.Key,
+			owner,
+			corev1.TolerationOpEqual,
+			corev1.TolerationOpExists,
+		)
+	}
+	if t.Key != "" && len(validation.IsQualifiedName(t.Key)) > 0 {
+		return validationError("toleration key %s of %s is not valid", t.Key, owner)
+	}
+
+	switch corev1.TaintEffect(t.Effect) {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule,
+		corev1.TaintEffectNoExecute:
+	default:
+		return validationError(
+			"effect %s of toleration %s of %s should be one of %s, %s, %s",
+			t.Effect,
+			t.Key,
+			owner,
+			corev1.TaintEffectNoSchedule,
+			corev1.TaintEffectPreferNoSchedule,
+			corev1.TaintEffectNoExecute,
+		)
+	}
+	if t.TolerationSeconds != nil && corev1.TaintEffect(t.Effect) != corev1.TaintEffectNoExecute {
+		return validationError(
+			"toleration %s of %s can only have tolerationSeconds with effect %s",
+			t.Key,
+			owner,
+			corev1.TaintEffectNoExecute,
+		)
+	}
+	return nil
+}
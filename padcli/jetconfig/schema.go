@@ -0,0 +1,215 @@
+package jetconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.jetpack.io/launchpad/proto/api"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchema is the subset of JSON Schema (draft-07) needed to describe
+// launchpad.yaml. The same schema is printed for editors and used by
+// ValidateFile, so whatever editors flag is also flagged by
+// `launchpad config validate`.
+type jsonSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+
+	Properties map[string]*jsonSchema `json:"properties,omitempty"`
+	// AdditionalProperties is either false or a *jsonSchema
+	AdditionalProperties any      `json:"additionalProperties,omitempty"`
+	Required             []string `json:"required,omitempty"`
+
+	Items *jsonSchema `json:"items,omitempty"`
+
+	Const     string   `json:"const,omitempty"`
+	Enum      []string `json:"enum,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MinLength int      `json:"minLength,omitempty"`
+
+	OneOf []*jsonSchema `json:"oneOf,omitempty"`
+	AnyOf []*jsonSchema `json:"anyOf,omitempty"`
+
+	// environmentKeys marks the per-environment alternative of a map or struct
+	// field, whose keys can only be environments. See configSchemaFor.
+	environmentKeys bool
+}
+
+// schemaProvider is implemented by types with custom yaml unmarshalling, so
+// that their schema matches what they accept rather than their go type.
+type schemaProvider interface {
+	jsonSchema() *jsonSchema
+}
+
+var schemaProviderType = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+
+// JSONSchema returns the JSON Schema for launchpad.yaml. Editors can use it
+// for autocompletion and validation, e.g. by adding
+// `# yaml-language-server: $schema=<path-to-schema>` to launchpad.yaml.
+func JSONSchema() ([]byte, error) {
+	b, err := json.MarshalIndent(configSchema(), "", "  ")
+	return b, errors.WithStack(err)
+}
+
+func configSchema() *jsonSchema {
+	s := schemaForType(reflect.TypeOf(Config{}))
+	s.Schema = jsonSchemaDraft
+	s.Title = defaultFileName
+	s.Description = "Launchpad project config. " +
+		"See https://www.jetpack.io/launchpad/docs/reference/launchpad.yaml-reference/"
+	s.Required = []string{"configVersion", "name", "projectId"}
+	s.Properties["name"].MinLength = minNameLength
+	s.Properties["projectId"].Pattern = "^proj_"
+	return s
+}
+
+// configSchemaFor returns the schema of a config that declares envs. Unlike
+// the printed schema, the per-environment values of map and struct fields only
+// accept built-in and declared environments, so that any other key is reported
+// as an unknown field.
+func configSchemaFor(envs []string) *jsonSchema {
+	s := configSchema()
+	restrictEnvironmentKeys(s, envs, map[*jsonSchema]bool{})
+	return s
+}
+
+func restrictEnvironmentKeys(s *jsonSchema, envs []string, seen map[*jsonSchema]bool) {
+	if s == nil || seen[s] {
+		return
+	}
+	seen[s] = true
+	if s.environmentKeys {
+		for _, env := range envs {
+			s.Properties[env] = s.AdditionalProperties.(*jsonSchema)
+		}
+		s.AdditionalProperties = false
+	}
+	children := append(append(maps.Values(s.Properties), s.Items), s.OneOf...)
+	children = append(children, s.AnyOf...)
+	if ap, ok := s.AdditionalProperties.(*jsonSchema); ok {
+		children = append(children, ap)
+	}
+	for _, child := range children {
+		restrictEnvironmentKeys(child, envs, seen)
+	}
+}
+
+func schemaForType(t reflect.Type) *jsonSchema {
+	if t.Kind() == reflect.Pointer {
+		return schemaForType(t.Elem())
+	}
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).jsonSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{
+			Type:                 "object",
+			AdditionalProperties: schemaForType(t.Elem()),
+		}
+	case reflect.Struct:
+		s := &jsonSchema{
+			Type:                 "object",
+			Properties:           map[string]*jsonSchema{},
+			AdditionalProperties: false,
+		}
+		addStructProperties(s, t)
+		return s
+	default:
+		// interfaces (e.g. FlagSet values) can be anything
+		return &jsonSchema{}
+	}
+}
+
+// addStructProperties adds the fields of struct t to s.
+func addStructProperties(s *jsonSchema, t reflect.Type) {
+	for name, ft := range yamlFields(t) {
+		s.Properties[name] = schemaForType(ft)
+	}
+}
+
+// yamlFields returns the types of the fields of struct t by yaml key,
+// mirroring how yaml.v3 maps struct fields to keys.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && strings.Contains(opts, "inline") {
+			maps.Copy(fields, yamlFields(f.Type))
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func (services) jsonSchema() *jsonSchema {
+	types := maps.Keys(serviceFactories)
+	slices.Sort(types)
+
+	svcSchema := &jsonSchema{}
+	for _, typ := range types {
+		s := schemaForType(reflect.TypeOf(serviceFactories[typ]()))
+		s.Properties["type"] = &jsonSchema{Type: "string", Const: typ}
+		s.Required = []string{"type"}
+		svcSchema.OneOf = append(svcSchema.OneOf, s)
+	}
+	return &jsonSchema{Type: "object", AdditionalProperties: svcSchema}
+}
+
+func (e envDependentField[T]) jsonSchema() *jsonSchema {
+	valueSchema := schemaForType(reflect.TypeOf((*T)(nil)).Elem())
+	envs := api.ValidLowercaseEnvironments()
+	slices.Sort(envs)
+
+	// Other keys are environments declared in the config
+	perEnv := &jsonSchema{
+		Type:                 "object",
+		Properties:           map[string]*jsonSchema{},
+		AdditionalProperties: valueSchema,
+	}
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Map, reflect.Struct:
+		perEnv.environmentKeys = true
+	}
+	for _, env := range envs {
+		perEnv.Properties[env] = valueSchema
+	}
+	// anyOf rather than oneOf, because a map value such as
+	// `env: {FOO: {secret: KEY}}` also reads as per-environment values.
+	return &jsonSchema{AnyOf: []*jsonSchema{valueSchema, perEnv}}
+}
+
+func (InstanceType) jsonSchema() *jsonSchema {
+	return &jsonSchema{Type: "string", Enum: validInstanceTypes()}
+}
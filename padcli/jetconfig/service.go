@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/samber/lo"
 )
 
 // TODO DEV-983 consolidate default helm value constants
@@ -13,14 +15,13 @@ const DefaultAppPodPort = 8080
 const (
 	CronType      = "cron"
 	HelmChartType = "helm"
+	InternalType  = "internal"
 	JobType       = "job"
 	WebType       = "web"
+	WorkerType    = "worker"
 
 	// other possible types:
-	// internal
 	// app - what is app??
-	// worker
-	// helm
 )
 
 // Service represents the core of a jetconfig service
@@ -73,35 +74,81 @@ func (t *service) GetUniqueName() string {
 // may be builders but may not have images. We may refactor when introducing
 // such builders.
 type Builder interface {
+	GetAnnotations() map[string]string
+	GetBuild() *Build
 	GetBuildCommand() string
+	GetConfigFiles() []ConfigFile
+	GetEnv() map[string]EnvVar
 	GetImage() string
+	GetInitContainers() []Container
 	GetInstanceType() *InstanceType
+	GetLabels() map[string]string
+	GetResources() Resources
 	GetPath() string
+	GetScheduling() Scheduling
+	GetSidecars() []Container
 	ShouldPublish() bool
 }
 
 type builder struct {
-	BuildCommand string        `yaml:"buildCommand,omitempty,flow"`
-	Image        string        `yaml:"image,omitempty"`
-	InstanceType *InstanceType `yaml:"instance,omitempty"`
+	cfg            *Config
+	BuildCommand   envDependentField[string]            `yaml:"buildCommand,omitempty,flow"`
+	Image          envDependentField[string]            `yaml:"image,omitempty"`
+	InstanceType   envDependentField[InstanceType]      `yaml:"instance,omitempty"`
+	Resources      envDependentField[Resources]         `yaml:"resources,omitempty"`
+	Env            map[string]envDependentField[EnvVar] `yaml:"env,omitempty"`
+	ConfigFiles    []ConfigFile                         `yaml:"configFiles,omitempty"`
+	Sidecars       []Container                          `yaml:"sidecars,omitempty"`
+	InitContainers []Container                          `yaml:"initContainers,omitempty"`
+	Build          *Build                               `yaml:"build,omitempty"`
+	Scheduling     envDependentField[Scheduling]        `yaml:"scheduling,omitempty"`
+	Labels         map[string]string                    `yaml:"labels,omitempty"`
+	Annotations    map[string]string                    `yaml:"annotations,omitempty"`
+}
+
+func (b *builder) setParent(p *Config) {
+	b.cfg = p
 }
 
 func (b *builder) GetBuildCommand() string {
-	return InterpolateStamps(b.BuildCommand)
+	return b.cfg.interpolate(b.BuildCommand.Get(b.cfg.env()))
 }
 
 func (b *builder) GetImage() string {
-	img := b.Image
+	img := b.Image.Get(b.cfg.env())
 	if b.ShouldPublish() {
-		img = strings.Replace(b.Image, "local/", "", 1)
+		img = strings.Replace(img, "local/", "", 1)
 	}
-	return InterpolateStamps(img)
+	return b.cfg.interpolate(img)
 }
 
 func (b *builder) GetInstanceType() *InstanceType {
-	return b.InstanceType
+	if it, ok := b.InstanceType.Lookup(b.cfg.env()); ok {
+		return &it
+	}
+	return nil
 }
 
 func (b *builder) ShouldPublish() bool {
-	return strings.HasPrefix(b.Image, "local/")
+	return strings.HasPrefix(b.Image.Get(b.cfg.env()), "local/")
+}
+
+func (b *builder) interpolatedFields() map[string]string {
+	fields := map[string]string{
+		"buildCommand": b.BuildCommand.Get(b.cfg.env()),
+		"image":        b.Image.Get(b.cfg.env()),
+	}
+	for name, field := range b.Env {
+		fields["env."+name] = field.Get(b.cfg.env()).Value
+	}
+	if b.Build != nil {
+		for name, v := range b.Build.Args {
+			fields["build.args."+name] = v
+		}
+	}
+	return lo.Assign(
+		fields,
+		b.containerInterpolatedFields(),
+		b.labelsInterpolatedFields(),
+	)
 }
@@ -8,6 +8,16 @@ import (
 
 type services []Service
 
+// serviceFactories returns an empty service struct for each service type
+var serviceFactories = map[string]func() Service{
+	CronType:      func() Service { return &cron{} },
+	HelmChartType: func() Service { return &helmChart{} },
+	InternalType:  func() Service { return &internal{} },
+	JobType:       func() Service { return &job{} },
+	WebType:       func() Service { return &web{} },
+	WorkerType:    func() Service { return &worker{} },
+}
+
 func (s *services) UnmarshalYAML(value *yaml.Node) error {
 	result := services{}
 
@@ -22,19 +32,11 @@ func (s *services) UnmarshalYAML(value *yaml.Node) error {
 			return errors.WithStack(err)
 		}
 
-		var svc Service
-		switch rawService.Type {
-		case CronType:
-			svc = &cron{}
-		case HelmChartType:
-			svc = &helmChart{}
-		case JobType:
-			svc = &job{}
-		case WebType:
-			svc = &web{}
-		default:
+		newService, ok := serviceFactories[rawService.Type]
+		if !ok {
 			return errors.Errorf("unknown service type: %s", rawService.Type)
 		}
+		svc := newService()
 
 		if err := pair[1].Decode(svc); err != nil {
 			return errors.WithStack(err)
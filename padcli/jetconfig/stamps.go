@@ -1,9 +1,16 @@
 package jetconfig
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/samber/lo"
+	"go.jetpack.io/launchpad/goutil/errorutil"
 )
 
 var stamps = map[string]string{
@@ -16,3 +23,137 @@ func InterpolateStamps(s string) string {
 	}
 	return s
 }
+
+// Variables that launchpad resolves itself. Environment variables must be
+// prefixed with envVariablePrefix, so that shell variables in commands, such
+// as ${PORT}, are left as is.
+const (
+	gitSHAVariable      = "GIT_SHA"
+	gitShortSHAVariable = "GIT_SHORT_SHA"
+	gitBranchVariable   = "GIT_BRANCH"
+	environmentVariable = "ENVIRONMENT"
+	projectIDVariable   = "PROJECT_ID"
+	envVariablePrefix   = "env."
+)
+
+// Matches ${VAR}, ${VAR:-default} and the $${ escape
+var variableRegex = regexp.MustCompile(
+	`\$\$\{|\$\{((?:env\.)?[A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`,
+)
+
+// Interpolate expands ${VAR} and ${VAR:-default} in s. VAR can be GIT_SHA,
+// GIT_SHORT_SHA, GIT_BRANCH, ENVIRONMENT, PROJECT_ID, or env.NAME for the
+// environment variable NAME. Other variables are left as is. The default is
+// used if VAR is unset or empty. $${ is a literal ${. Unresolved variables are
+// a user error.
+func (c *Config) Interpolate(s string) (string, error) {
+	unresolved := []string{}
+	result := variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		m := variableRegex.FindStringSubmatch(match)
+		name, defaultValue, hasDefault := m[1], strings.TrimPrefix(m[2], ":-"), m[2] != ""
+		v, ok, known := c.lookupVariable(name)
+		if !known {
+			return match
+		}
+		if ok && (v != "" || !hasDefault) {
+			return v
+		}
+		if hasDefault {
+			return defaultValue
+		}
+		unresolved = append(unresolved, name)
+		return match
+	})
+	if len(unresolved) > 0 {
+		unresolved = lo.Uniq(unresolved)
+		return "", errorutil.NewUserErrorf(
+			"unresolved variable(s) %s in %q. Set them in the environment, "+
+				"add a default such as ${%s:-value}, or escape them as $${%s} "+
+				"if they should be left as is",
+			strings.Join(unresolved, ", "),
+			s,
+			unresolved[0],
+			unresolved[0],
+		)
+	}
+	return InterpolateStamps(result), nil
+}
+
+// interpolate is like Interpolate, but leaves s as is if it has unresolved
+// variables. Config validation reports those.
+func (c *Config) interpolate(s string) string {
+	if result, err := c.Interpolate(s); err == nil {
+		return result
+	}
+	return InterpolateStamps(s)
+}
+
+// lookupVariable returns the value of variable name, and whether it's set.
+// known is false if name is not a variable that launchpad resolves.
+func (c *Config) lookupVariable(name string) (value string, ok bool, known bool) {
+	switch name {
+	case environmentVariable:
+		return c.env(), c.env() != "", true
+	case projectIDVariable:
+		if c != nil && c.ProjectID != "" {
+			return c.ProjectID, true, true
+		}
+		return "", false, true
+	case gitSHAVariable, gitShortSHAVariable, gitBranchVariable:
+		if v := c.gitVariables()[name]; v != "" {
+			return v, true, true
+		}
+		// e.g. CI builds without a .git directory may set these instead
+		value, ok = os.LookupEnv(name)
+		return value, ok, true
+	}
+	if strings.HasPrefix(name, envVariablePrefix) {
+		value, ok = os.LookupEnv(strings.TrimPrefix(name, envVariablePrefix))
+		return value, ok, true
+	}
+	return "", false, false
+}
+
+// gitVariables returns the git variables of the repository that contains the
+// config. They are empty if git or the repository are not available.
+func (c *Config) gitVariables() map[string]string {
+	if c == nil {
+		return map[string]string{}
+	}
+	if c.gitVars != nil {
+		return c.gitVars
+	}
+
+	dir := ""
+	if c.Path != "" {
+		dir = filepath.Dir(c.Path)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	c.gitVars = map[string]string{
+		gitSHAVariable:      git("rev-parse", "HEAD"),
+		gitShortSHAVariable: git("rev-parse", "--short", "HEAD"),
+		gitBranchVariable:   git("rev-parse", "--abbrev-ref", "HEAD"),
+	}
+	if c.gitVars[gitBranchVariable] == "HEAD" {
+		// detached HEAD, e.g. in CI
+		c.gitVars[gitBranchVariable] = ""
+	}
+	return c.gitVars
+}
+
+// interpolatedFields is implemented by services with fields that support
+// variables, keyed by field name, with the values for the selected environment.
+type interpolatedFields interface {
+	interpolatedFields() map[string]string
+}
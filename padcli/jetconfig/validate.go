@@ -1,30 +1,65 @@
 package jetconfig
 
 import (
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.jetpack.io/launchpad/goutil/errorutil"
 	"go.jetpack.io/launchpad/padcli/semver"
-	"go.jetpack.io/launchpad/proto/api"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
-var atMostOneWebServiceErr = validationError("At most one web service may be defined per jetconfig")
+const (
+	minNameLength = 4
+
+	validationErrorPrefix = "Invalid Jetconfig Error: "
+)
+
+type validationRule struct {
+	// field is the path of the launchpad.yaml field that the rule checks. It's
+	// used by ValidateFile to point at the offending line.
+	field string
+	check func(cfg *Config) error
+}
+
+var validationRules = []validationRule{
+	{"configVersion", requireConfigVersionRule},
+	{"configVersion", validConfigVersionRule},
+	{"name", requireNameRule},
+	{"name", validNameRule},
+	{"projectId", requireProjectIdRule},
+	{"projectId", validProjectIdRule},
+	{"cluster", requireClusterRule},
+	{"environment", validEnvironmentNamesRule},
+	{"environment", validJobRetentionRule},
+	{"services", declaredEnvironmentsRule},
+	{"services", validWorkerReplicasRule},
+	{"services", validWebReplicasRule},
+	{"services", reservedServiceNamesRule},
+	{"services", validJobPhaseRule},
+	{"services", validJobSpecRule},
+	{"services", validResourcesRule},
+	{"services", validHealthChecksRule},
+	{"services", validEnvRule},
+	{"services", validVolumesRule},
+	{"services", validContainersRule},
+	{"services", validHelmChartsRule},
+	{"services", validBuildRule},
+	{"services", validCronsRule},
+	{"services", validSchedulingRule},
+	{"services", interpolationRule},
+	{"services", validDomainsRule},
+	{"labels", validLabelsRule},
+	{"ingress", validIngressRule},
+	{"", validateSelectedEnvironmentRule},
+}
 
 func (cfg *Config) validate() error {
-	checkers := []func(cfg *Config) error{
-		requireConfigVersionRule,
-		validConfigVersionRule,
-		requireNameRule,
-		validNameRule,
-		requireProjectIdRule,
-		validProjectIdRule,
-		requireClusterRule,
-		atMostOneWebServiceRule,
-		validateSelectedEnvironmentRule,
-	}
-	for _, checker := range checkers {
-		if err := checker(cfg); err != nil {
+	for _, rule := range validationRules {
+		if err := rule.check(cfg); err != nil {
 			return errors.WithStack(err)
 		}
 	}
@@ -62,7 +97,6 @@ func requireNameRule(cfg *Config) error {
 }
 
 func validNameRule(cfg *Config) error {
-	const minNameLength = 4
 	if cfg.Name != "" && len(cfg.Name) < minNameLength {
 		return validationError("Name must be at least %d characters long", minNameLength)
 	}
@@ -96,30 +130,121 @@ func requireClusterRule(cfg *Config) error {
 	return nil
 }
 
-func atMostOneWebServiceRule(cfg *Config) error {
-	if len(cfg.Services) == 0 {
-		return nil
+func validationError(format string, args ...any) error {
+	return errorutil.NewUserErrorf(validationErrorPrefix+format, args...)
+}
+
+func validateSelectedEnvironmentRule(cfg *Config) error {
+	if cfg.selectedEnvironment == "" {
+		return validationError("Environment cannot be empty")
 	}
+	if !cfg.HasEnvironment(cfg.selectedEnvironment) {
+		return validationError(
+			"Environment %s is not declared. Add it under \"environment\" in your jetconfig, "+
+				"or use one of: %s",
+			cfg.selectedEnvironment,
+			strings.Join(cfg.Environments(), ", "),
+		)
+	}
+	return nil
+}
 
-	webCount := 0
-	for _, svc := range cfg.Services {
-		if _, ok := svc.(*web); ok {
-			webCount += 1
+func validEnvironmentNamesRule(cfg *Config) error {
+	for name := range cfg.Environment {
+		if !IsValidEnvironmentName(name) {
+			return validationError(
+				"Environment name %s must consist of lowercase alphanumeric characters or '-'",
+				name,
+			)
 		}
 	}
-	if webCount > 1 {
-		return atMostOneWebServiceErr
+	return nil
+}
+
+func validJobRetentionRule(cfg *Config) error {
+	for name, env := range cfg.Environment {
+		if env.JobRetention == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(env.JobRetention); err != nil {
+			return validationError(
+				"jobRetention %s of environment %s must be a duration such as 10m or 24h",
+				env.JobRetention,
+				name,
+			)
+		}
 	}
 	return nil
 }
 
-func validationError(msg ...any) error {
-	return errorutil.NewUserErrorf("Invalid Jetconfig Error: %s", msg...)
+// declaredEnvironmentsRule checks that the environments used in
+// environment-dependent service fields exist, to catch typos like "prdo".
+func declaredEnvironmentsRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		for _, env := range serviceEnvironments(reflect.ValueOf(svc)) {
+			if !cfg.HasEnvironment(env) {
+				return validationError(
+					"Service %s uses environment %s, which is not declared. "+
+						"Add it under \"environment\" in your jetconfig, or use one of: %s",
+					svc.GetName(),
+					env,
+					strings.Join(cfg.Environments(), ", "),
+				)
+			}
+		}
+	}
+	return nil
 }
 
-func validateSelectedEnvironmentRule(cfg *Config) error {
-	if cfg.selectedEnvironment == api.Environment_NONE {
-		return validationError("Environment cannot be NONE")
+func validWorkerReplicasRule(cfg *Config) error {
+	for _, w := range cfg.Workers() {
+		if w.GetReplicas() < 0 {
+			return validationError(
+				"replicas of worker %s must not be negative",
+				w.GetName(),
+			)
+		}
 	}
 	return nil
 }
+
+func interpolationRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		s, ok := svc.(interpolatedFields)
+		if !ok {
+			continue
+		}
+		fields := s.interpolatedFields()
+		names := maps.Keys(fields)
+		slices.Sort(names)
+		for _, name := range names {
+			if _, err := cfg.Interpolate(fields[name]); err != nil {
+				return validationError("Service %s %s: %v", svc.GetName(), name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// serviceEnvironments returns the environments used by the
+// environment-dependent fields of a service struct.
+func serviceEnvironments(v reflect.Value) []string {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return serviceEnvironments(v.Elem())
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	result := []string{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if v.Type().Field(i).Anonymous {
+			result = append(result, serviceEnvironments(f)...)
+		} else if !f.CanInterface() {
+			continue
+		} else if e, ok := f.Interface().(interface{ environments() []string }); ok {
+			result = append(result, e.environments()...)
+		}
+	}
+	return result
+}
@@ -1,10 +1,11 @@
 package jetconfig
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/suite"
-	"go.jetpack.io/launchpad/proto/api"
 )
 
 type ValidateSuite struct {
@@ -26,15 +27,75 @@ func (s *ValidateSuite) TestValidate() {
 		Services:      []Service{},
 	}
 	cfg.AddNewWebService("my-first-web-service")
-	cfg.selectedEnvironment = api.Environment_DEV
+	cfg.selectedEnvironment = "dev"
 
 	err := cfg.validate()
 	req.NoError(err)
 
 	cfg.AddNewWebService("my-second-web-service")
 	err = cfg.validate()
+	req.NoError(err)
+	req.Len(cfg.WebServices(), 2)
+
+	cfg.selectedEnvironment = ""
+	err = cfg.validate()
 	req.Error(err)
-	req.Equal(atMostOneWebServiceErr.Error(), err.Error())
 
 	//req.True(false)
 }
+
+func (s *ValidateSuite) TestValidateYamlContents() {
+	req := s.Require()
+
+	yamlContents := `configVersion: 0.1.2
+projectId: proj_1231231
+name: app
+cluster: my-cluster
+imageRepo: my-repo
+services:
+  api:
+    type: web
+    port: eighty
+    instance: huge
+  db:
+    type: database
+`
+	problems := validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev")
+	req.Equal(
+		[]string{
+			`3:7: must be at least 4 characters long`,
+			`5:1: unknown field "imageRepo". Did you mean "imageRepository"?`,
+			`9:11: expected integer or object but got string`,
+			`10:15: invalid value "huge". Valid values are: nano, micro, small, medium, medium_plus, large, xlarge, xxlarge`,
+			`12:11: invalid type "database". Valid values are: cron, helm, internal, job, web, worker`,
+		},
+		lo.Map(problems, func(p *ValidationError, _ int) string { return p.Error() }),
+	)
+
+	yamlContents = `configVersion: 0.1.2
+projectId: 1231231
+name: MyApp
+`
+	problems = validateYamlContents([]byte(yamlContents), "launchpad.yaml", "dev")
+	req.Equal(
+		[]string{
+			`1:1: Cluster is required. Run "jetpack cluster ls" to see a list of clusters available to you. Then add "cluster: <cluster-name>" to your jetconfig.`,
+			`2:12: "1231231" does not match ^proj_`,
+		},
+		lo.Map(problems, func(p *ValidationError, _ int) string { return p.Error() }),
+	)
+}
+
+func (s *ValidateSuite) TestJSONSchema() {
+	req := s.Require()
+
+	b, err := JSONSchema()
+	req.NoError(err)
+
+	schema := map[string]any{}
+	req.NoError(json.Unmarshal(b, &schema))
+	req.Equal(jsonSchemaDraft, schema["$schema"])
+	req.Contains(schema["properties"], "services")
+	req.Contains(schema["properties"], "imageRepository")
+	req.NotContains(schema["properties"], "Path")
+}
@@ -0,0 +1,433 @@
+package jetconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a single problem found in a launchpad.yaml file.
+type ValidationError struct {
+	// Path is the dotted path of the offending field (e.g. services.api.port)
+	Path    string
+	Line    int
+	Column  int
+	Message string
+
+	isUnknownField bool
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// ValidateFile checks the launchpad.yaml at path against the JSON Schema and
+// the validation rules, and returns every problem found rather than just
+// the first one. Unlike RequireFromFileSystem, it never upgrades the file.
+func ValidateFile(path string, env string) ([]*ValidationError, error) {
+	filePath := configPath(path)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, ErrConfigNotFound
+	}
+	yamlContents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read jetconfig file at %s", filePath)
+	}
+	return validateYamlContents(yamlContents, filePath, env), nil
+}
+
+func validateYamlContents(
+	yamlContents []byte,
+	filePath string,
+	env string,
+) []*ValidationError {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(yamlContents, doc); err != nil {
+		return []*ValidationError{yamlSyntaxError(err)}
+	}
+	if len(doc.Content) == 0 {
+		return []*ValidationError{{Line: 1, Column: 1, Message: "file is empty"}}
+	}
+	root := doc.Content[0]
+
+	problems := validateNode(configSchemaFor(environmentNames(root)), root, nil)
+
+	cfg := &Config{Path: filePath, selectedEnvironment: strings.ToLower(env)}
+	if err := root.Decode(cfg); err != nil {
+		if len(problems) == 0 {
+			problems = append(problems, nodeError(root, nil, err.Error()))
+		}
+		// Services are the most likely reason decoding failed. Decode everything
+		// else so that the rules can still run.
+		cfg = &Config{Path: filePath, selectedEnvironment: strings.ToLower(env)}
+		if err := withoutKey(root, "services").Decode(cfg); err != nil {
+			return sortValidationErrors(problems)
+		}
+	}
+
+	if cfg.AllowUnknownFields {
+		problems = lo.Reject(problems, func(p *ValidationError, _ int) bool {
+			return p.isUnknownField
+		})
+	}
+
+	for _, rule := range validationRules {
+		err := rule.check(cfg)
+		if err == nil || isFieldReported(problems, rule.field) {
+			continue
+		}
+		fieldPath := lo.Compact(strings.Split(rule.field, "."))
+		node := findNode(root, fieldPath)
+		if node == nil {
+			node = root
+		}
+		problems = append(problems, nodeError(
+			node,
+			fieldPath,
+			strings.TrimPrefix(err.Error(), validationErrorPrefix),
+		))
+	}
+
+	return sortValidationErrors(problems)
+}
+
+// validateNode validates a yaml node against a schema. It supports the
+// subset of JSON Schema produced by schemaForType.
+func validateNode(s *jsonSchema, node *yaml.Node, path []string) []*ValidationError {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		// Empty values decode to their zero value. Required fields are checked
+		// by the parent.
+		return nil
+	}
+	if len(s.OneOf) > 0 {
+		return validateOneOf(s, node, path)
+	}
+	if len(s.AnyOf) > 0 {
+		// Checked like oneOf, which already accepts the first match
+		return validateOneOf(&jsonSchema{OneOf: s.AnyOf}, node, path)
+	}
+	if s.Const != "" && node.Value != s.Const {
+		return []*ValidationError{
+			nodeError(node, path, fmt.Sprintf("must be %q", s.Const)),
+		}
+	}
+	if len(s.Enum) > 0 && !lo.Contains(s.Enum, node.Value) {
+		return []*ValidationError{nodeError(node, path, fmt.Sprintf(
+			"invalid value %q. Valid values are: %s",
+			node.Value,
+			strings.Join(s.Enum, ", "),
+		))}
+	}
+
+	switch s.Type {
+	case "object":
+		return validateMapping(s, node, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return []*ValidationError{typeError(node, path, s.Type)}
+		}
+		problems := []*ValidationError{}
+		for i, item := range node.Content {
+			problems = append(
+				problems,
+				validateNode(s.Items, item, append(slices.Clone(path), strconv.Itoa(i)))...,
+			)
+		}
+		return problems
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			return []*ValidationError{typeError(node, path, s.Type)}
+		}
+		if len(node.Value) < s.MinLength {
+			return []*ValidationError{nodeError(node, path, fmt.Sprintf(
+				"must be at least %d characters long",
+				s.MinLength,
+			))}
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+			return []*ValidationError{nodeError(node, path, fmt.Sprintf(
+				"%q does not match %s",
+				node.Value,
+				s.Pattern,
+			))}
+		}
+	case "integer", "boolean", "number":
+		tags := map[string][]string{
+			"integer": {"!!int"},
+			"boolean": {"!!bool"},
+			"number":  {"!!int", "!!float"},
+		}[s.Type]
+		if node.Kind != yaml.ScalarNode || !lo.Contains(tags, node.Tag) {
+			return []*ValidationError{typeError(node, path, s.Type)}
+		}
+	}
+	return nil
+}
+
+func validateMapping(s *jsonSchema, node *yaml.Node, path []string) []*ValidationError {
+	if node.Kind != yaml.MappingNode {
+		return []*ValidationError{typeError(node, path, s.Type)}
+	}
+
+	problems := []*ValidationError{}
+	seen := map[string]bool{}
+	for _, pair := range lo.Chunk(node.Content, 2) {
+		if len(pair) != 2 {
+			continue
+		}
+		key, value := pair[0], pair[1]
+		keyPath := append(slices.Clone(path), key.Value)
+		seen[key.Value] = true
+
+		propSchema, ok := s.Properties[key.Value]
+		if !ok {
+			switch ap := s.AdditionalProperties.(type) {
+			case *jsonSchema:
+				propSchema = ap
+			case bool:
+				if !ap {
+					problems = append(problems, unknownFieldError(key, keyPath, s))
+					continue
+				}
+			}
+		}
+		if propSchema != nil {
+			problems = append(problems, validateNode(propSchema, value, keyPath)...)
+		}
+	}
+
+	for _, required := range s.Required {
+		if !seen[required] {
+			problems = append(problems, nodeError(
+				node,
+				append(slices.Clone(path), required),
+				fmt.Sprintf("missing required field %q", required),
+			))
+		}
+	}
+	return problems
+}
+
+// validateOneOf returns no problems if the node matches any of the schemas.
+// Otherwise it reports the problems of the schema the user most likely
+// meant: the one selected by a discriminator field (e.g. a service's type), or
+// the one whose type matches the node's kind.
+func validateOneOf(s *jsonSchema, node *yaml.Node, path []string) []*ValidationError {
+	for _, option := range s.OneOf {
+		if len(validateNode(option, node, path)) == 0 {
+			return nil
+		}
+	}
+
+	if discriminator, consts := oneOfDiscriminator(s); discriminator != "" &&
+		node.Kind == yaml.MappingNode {
+		value := findNode(node, []string{discriminator})
+		if value == nil {
+			return []*ValidationError{nodeError(
+				node,
+				append(slices.Clone(path), discriminator),
+				fmt.Sprintf("missing required field %q", discriminator),
+			)}
+		}
+		idx := slices.Index(consts, value.Value)
+		if idx < 0 {
+			return []*ValidationError{nodeError(
+				value,
+				append(slices.Clone(path), discriminator),
+				fmt.Sprintf(
+					"invalid %s %q. Valid values are: %s",
+					discriminator,
+					value.Value,
+					strings.Join(consts, ", "),
+				),
+			)}
+		}
+		return validateNode(s.OneOf[idx], node, path)
+	}
+
+	for _, option := range s.OneOf {
+		if option.Type == nodeKindToSchemaType(node) {
+			return validateNode(option, node, path)
+		}
+	}
+	return []*ValidationError{typeError(
+		node,
+		path,
+		strings.Join(lo.Map(s.OneOf, func(o *jsonSchema, _ int) string {
+			return o.Type
+		}), " or "),
+	)}
+}
+
+// oneOfDiscriminator returns the property that has a different const value in
+// each of the options, along with those values.
+func oneOfDiscriminator(s *jsonSchema) (string, []string) {
+	for name, prop := range s.OneOf[0].Properties {
+		if prop.Const == "" {
+			continue
+		}
+		consts := []string{}
+		for _, option := range s.OneOf {
+			if p, ok := option.Properties[name]; ok && p.Const != "" {
+				consts = append(consts, p.Const)
+			}
+		}
+		if len(consts) == len(s.OneOf) {
+			return name, consts
+		}
+	}
+	return "", nil
+}
+
+func nodeKindToSchemaType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!int":
+		return "integer"
+	case "!!bool":
+		return "boolean"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+// findNode returns the value node at path, or nil if it doesn't exist.
+func findNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for _, pair := range lo.Chunk(node.Content, 2) {
+			if len(pair) == 2 && pair[0].Value == key {
+				next = pair[1]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// withoutKey returns a shallow copy of a mapping node without the given key.
+func withoutKey(node *yaml.Node, key string) *yaml.Node {
+	result := *node
+	result.Content = []*yaml.Node{}
+	for _, pair := range lo.Chunk(node.Content, 2) {
+		if pair[0].Value != key {
+			result.Content = append(result.Content, pair...)
+		}
+	}
+	return &result
+}
+
+func isFieldReported(problems []*ValidationError, field string) bool {
+	return lo.SomeBy(problems, func(p *ValidationError) bool {
+		return p.Path == field || strings.HasPrefix(p.Path, field+".")
+	})
+}
+
+func unknownFieldError(key *yaml.Node, path []string, s *jsonSchema) *ValidationError {
+	msg := fmt.Sprintf("unknown field %q", key.Value)
+	if suggestion := closestMatch(key.Value, maps.Keys(s.Properties)); suggestion != "" {
+		msg += fmt.Sprintf(". Did you mean %q?", suggestion)
+	}
+	problem := nodeError(key, path, msg)
+	problem.isUnknownField = true
+	return problem
+}
+
+// closestMatch returns the option that is most similar to s, or an empty
+// string if none of the options is close enough to be a likely typo.
+func closestMatch(s string, options []string) string {
+	slices.Sort(options) // for deterministic results
+	best, bestDistance := "", 0
+	for _, option := range options {
+		d := editDistance(strings.ToLower(s), strings.ToLower(option))
+		if d > len(option)/3+1 {
+			// Too different, even if it's closer than the other options
+			continue
+		}
+		if best == "" || d < bestDistance {
+			best, bestDistance = option, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = lo.Min([]int{prev[j] + 1, curr[j-1] + 1, prev[j-1] + cost})
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func typeError(node *yaml.Node, path []string, expected string) *ValidationError {
+	return nodeError(node, path, fmt.Sprintf(
+		"expected %s but got %s",
+		expected,
+		nodeKindToSchemaType(node),
+	))
+}
+
+func nodeError(node *yaml.Node, path []string, msg string) *ValidationError {
+	return &ValidationError{
+		Path:    strings.Join(path, "."),
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: msg,
+	}
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+func yamlSyntaxError(err error) *ValidationError {
+	line := 1
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ = strconv.Atoi(m[1])
+	}
+	return &ValidationError{Line: line, Column: 1, Message: err.Error()}
+}
+
+func sortValidationErrors(problems []*ValidationError) []*ValidationError {
+	slices.SortStableFunc(problems, func(a, b *ValidationError) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return problems
+}
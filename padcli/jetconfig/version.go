@@ -14,7 +14,7 @@ const (
 
 // singleton (unenforced) for this package
 var Versions = version{
-	min:  "0.1.2", // update this when we drop support for upgrading these older versions
+	min:  "0.1.0", // update this when we drop support for upgrading these older versions
 	prod: "0.1.2", // update this when ready to upgrade everyone to the new official version
 	dev:  "0.1.2", // update this when developing a new version
 }
@@ -35,13 +35,21 @@ func (v version) Dev() string {
 	return v.dev
 }
 
-func isVersionLessThanMinimumSupported(cfgVersion string) (bool, error) {
-	if cfgVersion == legacyVersionOneDotZero || cfgVersion == legacyVersionOneDotOne {
-		return true, nil
+// semverOf returns the semver version that cfgVersion corresponds to. Legacy
+// versions predate semver.
+func semverOf(cfgVersion string) string {
+	switch cfgVersion {
+	case legacyVersionOneDotZero:
+		return "0.1.0"
+	case legacyVersionOneDotOne:
+		return "0.1.1"
 	}
+	return cfgVersion
+}
 
+func isVersionLessThanMinimumSupported(cfgVersion string) (bool, error) {
 	// This comparison ensures that this function is kept forward-compatible
-	cmp, err := semver.Compare(cfgVersion, Versions.MinSupported())
+	cmp, err := semver.Compare(semverOf(cfgVersion), Versions.MinSupported())
 	if err != nil {
 		return false, errors.WithStack(err)
 	}
@@ -50,7 +58,7 @@ func isVersionLessThanMinimumSupported(cfgVersion string) (bool, error) {
 
 func doesVersionNeedUpgrade(cfgVersion string) (bool, error) {
 	// This comparison ensures that this function is kept forward-compatible
-	cmp, err := semver.Compare(cfgVersion, Versions.Prod())
+	cmp, err := semver.Compare(semverOf(cfgVersion), Versions.Prod())
 	if err != nil {
 		return false, errors.WithStack(err)
 	}
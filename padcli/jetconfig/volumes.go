@@ -0,0 +1,145 @@
+package jetconfig
+
+import (
+	"path"
+	"regexp"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Volume is persistent storage mounted into a service's containers. It's
+// backed by a PersistentVolumeClaim that outlives deploys, and that
+// `launchpad down` only deletes if asked to:
+//
+//	volumes:
+//	  - name: data
+//	    mountPath: /var/lib/postgresql/data
+//	    size: 10Gi
+type Volume struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	// Size is a Kubernetes quantity, e.g. 10Gi
+	Size string `yaml:"size"`
+	// StorageClass defaults to the cluster's default storage class.
+	StorageClass string `yaml:"storageClass,omitempty"`
+	// AccessMode defaults to ReadWriteOnce, which means the volume can only be
+	// mounted by pods on a single node. Services that can run more than one
+	// replica need ReadWriteMany or ReadOnlyMany.
+	AccessMode VolumeAccessMode `yaml:"accessMode,omitempty"`
+}
+
+// VolumeAccessMode is a Kubernetes PersistentVolumeClaim access mode.
+type VolumeAccessMode string
+
+const (
+	ReadWriteOnce    VolumeAccessMode = "ReadWriteOnce"
+	ReadOnlyMany     VolumeAccessMode = "ReadOnlyMany"
+	ReadWriteMany    VolumeAccessMode = "ReadWriteMany"
+	ReadWriteOncePod VolumeAccessMode = "ReadWriteOncePod"
+)
+
+var validVolumeAccessModes = []string{
+	string(ReadWriteOnce),
+	string(ReadOnlyMany),
+	string(ReadWriteMany),
+	string(ReadWriteOncePod),
+}
+
+func (VolumeAccessMode) jsonSchema() *jsonSchema {
+	return &jsonSchema{Type: "string", Enum: validVolumeAccessModes}
+}
+
+// GetAccessMode returns the access mode of the volume, or the default.
+func (v Volume) GetAccessMode() VolumeAccessMode {
+	if v.AccessMode == "" {
+		return ReadWriteOnce
+	}
+	return v.AccessMode
+}
+
+// volumeMounter is embedded by services that can have volumes.
+type volumeMounter struct {
+	Volumes []Volume `yaml:"volumes,omitempty"`
+}
+
+func (v *volumeMounter) GetVolumes() []Volume {
+	return v.Volumes
+}
+
+var volumeNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func validVolumesRule(cfg *Config) error {
+	for _, svc := range cfg.Services {
+		s, ok := svc.(interface{ GetVolumes() []Volume })
+		if !ok {
+			continue
+		}
+		names := map[string]bool{}
+		for _, v := range s.GetVolumes() {
+			if !volumeNamePattern.MatchString(v.Name) {
+				return validationError(
+					"volume name %q of service %s must consist of lower case letters, "+
+						"numbers and '-'",
+					v.Name,
+					svc.GetName(),
+				)
+			}
+			if names[v.Name] {
+				return validationError(
+					"volume %s of service %s is declared more than once",
+					v.Name,
+					svc.GetName(),
+				)
+			}
+			names[v.Name] = true
+			if !path.IsAbs(v.MountPath) {
+				return validationError(
+					"volume %s of service %s must have an absolute mountPath",
+					v.Name,
+					svc.GetName(),
+				)
+			}
+			if _, err := resource.ParseQuantity(v.Size); err != nil || v.Size == "" {
+				return validationError(
+					"volume %s of service %s must have a size such as 10Gi",
+					v.Name,
+					svc.GetName(),
+				)
+			}
+			if v.AccessMode != "" &&
+				!lo.Contains(validVolumeAccessModes, string(v.AccessMode)) {
+				return validationError(
+					"accessMode %s of volume %s of service %s should be one of %v",
+					v.AccessMode,
+					v.Name,
+					svc.GetName(),
+					validVolumeAccessModes,
+				)
+			}
+			mode := v.GetAccessMode()
+			if (mode == ReadWriteOnce || mode == ReadWriteOncePod) && canRunManyReplicas(svc) {
+				return validationError(
+					"volume %s of service %s is %s, so it can't be mounted by more "+
+						"than one replica. Use accessMode ReadWriteMany, or a single replica",
+					v.Name,
+					svc.GetName(),
+					mode,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// canRunManyReplicas returns true if svc may run more than one pod at a time.
+func canRunManyReplicas(svc Service) bool {
+	switch s := svc.(type) {
+	case *web:
+		r := s.GetReplicas()
+		return r.IsAutoscaled() || (r != nil && r.Min > 1)
+	case *worker:
+		return s.GetReplicas() > 1
+	}
+	return false
+}
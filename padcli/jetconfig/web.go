@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"go.jetpack.io/launchpad/proto/api"
 )
 
@@ -12,37 +14,51 @@ type Web interface {
 	Builder
 	Service
 	GetPort() int
+	GetDomains() []string
 	GetURL() (*url.URL, error)
+	GetHealthCheck() *HealthCheck
+	GetReplicas() *Replicas
+	GetVolumes() []Volume
 }
 
 // instantiates a new Web service for initcmd
 func (c *Config) AddNewWebService(name string) Web {
 	webSvc := &web{
-		Port: DefaultAppPodPort,
+		Port: newEnvDependentField(DefaultAppPodPort),
 		service: service{
-			parent: c,
-			name:   name,
-			Type:   WebType,
+			name: name,
+			Type: WebType,
 		},
 	}
+	webSvc.setParent(c)
 	c.Services = append(c.Services, webSvc)
 	return webSvc
 }
 
 type web struct {
-	service `yaml:",inline,omitempty"`
-	builder `yaml:",inline,omitempty"`
-	// Port may be used by other services like "internal",
-	// so we may move this to an interface
-	Port int                       `yaml:"port,omitempty"`
-	URL  envDependentField[string] `yaml:"url,omitempty"`
+	service       `yaml:",inline,omitempty"`
+	builder       `yaml:",inline,omitempty"`
+	healthChecker `yaml:",inline,omitempty"`
+	volumeMounter `yaml:",inline,omitempty"`
+	Port          envDependentField[int]      `yaml:"port,omitempty"`
+	URL           envDependentField[string]   `yaml:"url,omitempty"`
+	Replicas      envDependentField[Replicas] `yaml:"replicas,omitempty"`
+	// Domains are custom domains that the service is reachable at over HTTPS.
+	// See IngressFields.
+	Domains envDependentField[[]string] `yaml:"domains,omitempty,flow"`
+}
+
+func (w *web) setParent(p *Config) {
+	w.service.setParent(p)
+	w.builder.setParent(p)
+	w.healthChecker.setParent(p)
 }
 
 func (w *web) GetPort() int {
-	if w == nil || w.Port == 0 {
+	if w == nil || w.Port.Get(w.parent.env()) == 0 {
 		return DefaultAppPodPort
 	}
-	return w.Port
+	return w.Port.Get(w.parent.env())
 }
 
 var scheme = regexp.MustCompile(`^https?://`)
@@ -51,10 +67,9 @@ func (w *web) GetURL() (*url.URL, error) {
 	if w == nil {
 		return nil, nil
 	}
-	u, ok := w.URL[w.parent.selectedEnvironment]
-	if !ok && w.parent.selectedEnvironment == api.Environment_PROD {
-		// only use non-env url if prod
-		u = w.URL[api.Environment_NONE]
+	u, err := w.parent.Interpolate(w.rawURL())
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 	if strings.HasPrefix(u, "/") || scheme.MatchString(u) {
 		return url.Parse(u)
@@ -62,4 +77,21 @@ func (w *web) GetURL() (*url.URL, error) {
 	return url.Parse("https://" + u)
 }
 
+func (w *web) rawURL() string {
+	u, ok := w.URL[w.parent.env()]
+	if !ok && w.parent.env() == api.Environment_PROD.ToLower() {
+		// only use non-env url if prod
+		u = w.URL[allEnvironments]
+	}
+	return u
+}
+
+func (w *web) interpolatedFields() map[string]string {
+	return lo.Assign(
+		w.builder.interpolatedFields(),
+		w.domainsInterpolatedFields(),
+		map[string]string{"url": w.rawURL()},
+	)
+}
+
 var _ Web = (*web)(nil)
@@ -0,0 +1,86 @@
+package jetconfig
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Worker is a long running service that is not exposed on the network, e.g. a
+// queue consumer. It's deployed without a kubernetes Service or ingress.
+type Worker interface {
+	Builder
+	Service
+	GetCommand() []string
+	GetReplicas() int
+	GetHealthCheck() *HealthCheck
+	GetVolumes() []Volume
+}
+
+const defaultWorkerReplicas = 1
+
+// instantiates a new Worker service for initcmd
+func (c *Config) AddNewWorkerService(name string, command []string) Worker {
+	newWorker := &worker{
+		Command: newEnvDependentField(command),
+		builder: builder{
+			Image: newEnvDependentField("busybox:latest"),
+		},
+		service: service{
+			name: name,
+			Type: WorkerType,
+		},
+	}
+	newWorker.setParent(c)
+	c.Services = append(c.Services, newWorker)
+	return newWorker
+}
+
+// Private worker struct
+type worker struct {
+	service       `yaml:",inline,omitempty"`
+	builder       `yaml:",inline,omitempty"`
+	healthChecker `yaml:",inline,omitempty"`
+	volumeMounter `yaml:",inline,omitempty"`
+	Command       envDependentField[[]string] `yaml:"command,omitempty,flow"`
+	Replicas      envDependentField[int]      `yaml:"replicas,omitempty"`
+}
+
+var _ Worker = (*worker)(nil)
+
+func (w *worker) setParent(p *Config) {
+	w.service.setParent(p)
+	w.builder.setParent(p)
+	w.healthChecker.setParent(p)
+}
+
+func (w *worker) GetCommand() []string {
+	return lo.Map(w.Command.Get(w.parent.env()), func(arg string, _ int) string {
+		return w.parent.interpolate(arg)
+	})
+}
+
+func (w *worker) interpolatedFields() map[string]string {
+	return lo.Assign(w.builder.interpolatedFields(), map[string]string{
+		"command": strings.Join(w.Command.Get(w.parent.env()), " "),
+	})
+}
+
+func (w *worker) GetReplicas() int {
+	if replicas, ok := w.Replicas.Lookup(w.parent.env()); ok {
+		return replicas
+	}
+	return defaultWorkerReplicas
+}
+
+// Workers returns the worker services in the order they are defined in the
+// jetconfig. Each worker is installed as its own app release.
+func (c *Config) Workers() []Worker {
+	result := []Worker{}
+	for _, svc := range c.Services {
+		if w, ok := svc.(*worker); ok {
+			result = append(result, w)
+		}
+	}
+	return result
+}
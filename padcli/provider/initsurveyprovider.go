@@ -15,6 +15,8 @@ type serviceTypeOption string
 const (
 	WebServiceType             serviceTypeOption = "Web Service"
 	CronjobServiceType         serviceTypeOption = "Cron Job"
+	WorkerServiceType          serviceTypeOption = "Worker"
+	InternalServiceType        serviceTypeOption = "Internal Service"
 	JetpackManagedCluster      string            = "Jetpack managed cluster"
 	CreateJetpackCluster       string            = "Create a new cluster with Jetpack"
 	ImageRepositoryFlagHelpMsg                   = "Image repository to push the built image to. " +
@@ -168,6 +170,10 @@ func getAppTypeOptions(appTypes []string) []string {
 			appTypeOptions = append(appTypeOptions, string(WebServiceType))
 		case jetconfig.CronType:
 			appTypeOptions = append(appTypeOptions, string(CronjobServiceType))
+		case jetconfig.WorkerType:
+			appTypeOptions = append(appTypeOptions, string(WorkerServiceType))
+		case jetconfig.InternalType:
+			appTypeOptions = append(appTypeOptions, string(InternalServiceType))
 		}
 	}
 	return appTypeOptions
@@ -5,11 +5,13 @@ import (
 
 	"github.com/pkg/errors"
 	"go.jetpack.io/launchpad/padcli/kubeconfig"
-	"go.jetpack.io/launchpad/proto/api"
 )
 
 type NamespaceProvider interface {
-	Get(ctx context.Context, ns string, kubeContext string, e api.Environment) (string, error)
+	// Get returns the namespace to use for env, which is the name of a
+	// built-in environment or one declared in launchpad.yaml. ns is the
+	// namespace that the user picked, if any.
+	Get(ctx context.Context, ns string, kubeContext string, env string) (string, error)
 }
 
 func KubeConfigNamespaceProvider() NamespaceProvider {
@@ -24,7 +26,7 @@ func (p *kubeConfigNamespaceProvider) Get(
 	ctx context.Context,
 	ns string,
 	kubeContextName string,
-	e api.Environment,
+	env string,
 ) (string, error) {
 	if ns != "" {
 		return ns, nil
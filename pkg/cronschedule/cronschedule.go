@@ -0,0 +1,219 @@
+// Package cronschedule parses the cron schedules of Kubernetes CronJobs, so
+// that they can be validated before they are deployed.
+//
+// Schedules have five fields: minute, hour, day of month, month and day of
+// week. Fields may be *, ?, a value, a range a-b, a step */n or a-b/n, or a
+// comma separated list of these. Months and days of week may also be names
+// such as JAN or MON. The macros @yearly, @annually, @monthly, @weekly,
+// @daily, @midnight and @hourly are supported too.
+package cronschedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit sets of the matching values
+
+	// A schedule with both day of month and day of week restricted runs when
+	// either matches, as in cron.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteBounds = bounds{"minute", 0, 59, nil}
+	hourBounds   = bounds{"hour", 0, 23, nil}
+	domBounds    = bounds{"day of month", 1, 31, nil}
+	monthBounds  = bounds{"month", 1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = bounds{"day of week", 0, 6, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron schedule. Time zones are not part of the schedule, see
+// the timeZone field of CronJobs instead.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, errors.New("time zones are not supported in the schedule")
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := macros[strings.ToLower(spec)]
+		if !ok {
+			return nil, errors.Errorf("unknown macro %s", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("expected 5 fields, found %d", len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	for _, f := range []struct {
+		field  string
+		bounds bounds
+		bits   *uint64
+	}{
+		{fields[0], minuteBounds, &s.minute},
+		{fields[1], hourBounds, &s.hour},
+		{fields[2], domBounds, &s.dom},
+		{fields[3], monthBounds, &s.month},
+		{fields[4], dowBounds, &s.dow},
+	} {
+		if *f.bits, err = parseField(f.field, f.bounds); err != nil {
+			return nil, err
+		}
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeAndStep := strings.Split(expr, "/")
+		if len(rangeAndStep) > 2 {
+			return 0, errors.Errorf("invalid %s %q", b.name, expr)
+		}
+
+		var start, end uint
+		switch r := rangeAndStep[0]; {
+		case r == "*" || r == "?":
+			start, end = b.min, b.max
+		default:
+			startAndEnd := strings.Split(r, "-")
+			if len(startAndEnd) > 2 {
+				return 0, errors.Errorf("invalid %s %q", b.name, expr)
+			}
+			var err error
+			if start, err = parseValue(startAndEnd[0], b); err != nil {
+				return 0, err
+			}
+			end = start
+			if len(startAndEnd) == 2 {
+				if end, err = parseValue(startAndEnd[1], b); err != nil {
+					return 0, err
+				}
+			} else if len(rangeAndStep) == 2 {
+				// a/n means from a to the end, every n
+				end = b.max
+			}
+		}
+		if start > end {
+			return 0, errors.Errorf("invalid %s range %q", b.name, expr)
+		}
+
+		step := uint(1)
+		if len(rangeAndStep) == 2 {
+			n, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+			if err != nil || n == 0 {
+				return 0, errors.Errorf("invalid %s step %q", b.name, expr)
+			}
+			step = uint(n)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(v) < b.min || uint(v) > b.max {
+		return 0, errors.Errorf(
+			"invalid %s %q, expected a value from %d to %d",
+			b.name,
+			s,
+			b.min,
+			b.max,
+		)
+	}
+	return uint(v), nil
+}
+
+// maxSearchYears bounds the search for the next run, e.g. for schedules such
+// as 0 0 30 2 * that never run.
+const maxSearchYears = 5
+
+// Next returns the first time after t that the schedule runs, in t's
+// location, or the zero time if it never runs.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// NextN returns the next n times after t that the schedule runs.
+func (s *Schedule) NextN(t time.Time, n int) []time.Time {
+	times := []time.Time{}
+	for len(times) < n {
+		if t = s.Next(t); t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,107 @@
+package cronschedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		spec string
+		err  string
+	}{
+		{"* * * *", "expected 5 fields, found 4"},
+		{"60 * * * *", `invalid minute "60", expected a value from 0 to 59`},
+		{"* 24 * * *", `invalid hour "24"`},
+		{"* * 0 * *", `invalid day of month "0"`},
+		{"* * * 13 * ", `invalid month "13"`},
+		{"* * * * 7", `invalid day of week "7"`},
+		{"* * * * funday", `invalid day of week "funday"`},
+		{"*/0 * * * *", `invalid minute step "*/0"`},
+		{"10-5 * * * *", `invalid minute range "10-5"`},
+		{"1-2-3 * * * *", `invalid minute "1-2-3"`},
+		{"@often", "unknown macro @often"},
+		{"TZ=UTC 0 * * * *", "time zones are not supported"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := Parse(tc.spec)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2023, time.March, 15, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		spec string
+		want []time.Time
+	}{
+		{
+			"*/20 * * * *",
+			[]time.Time{
+				time.Date(2023, time.March, 15, 10, 40, 0, 0, time.UTC),
+				time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 15, 11, 20, 0, 0, time.UTC),
+			},
+		},
+		{
+			"@daily",
+			[]time.Time{
+				time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 18, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 9 * * MON-FRI",
+			[]time.Time{
+				time.Date(2023, time.March, 16, 9, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 17, 9, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 20, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// Day of month or day of week
+			"0 0 1 * SUN",
+			[]time.Time{
+				time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, time.March, 26, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"15 10/6 29 feb ?",
+			[]time.Time{
+				time.Date(2024, time.February, 29, 10, 15, 0, 0, time.UTC),
+				time.Date(2024, time.February, 29, 16, 15, 0, 0, time.UTC),
+				time.Date(2024, time.February, 29, 22, 15, 0, 0, time.UTC),
+			},
+		},
+		{"0 0 30 2 *", []time.Time{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := Parse(tc.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, s.NextN(from, 3))
+		})
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	s, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	// 06:30 in New York, which is on daylight saving time
+	from := time.Date(2023, time.March, 15, 10, 30, 0, 0, time.UTC).In(loc)
+	next := s.Next(from)
+	assert.Equal(t, time.Date(2023, time.March, 15, 9, 0, 0, 0, loc), next)
+	assert.Equal(t, time.Date(2023, time.March, 15, 13, 0, 0, 0, time.UTC), next.UTC())
+}
@@ -17,6 +17,7 @@ type BuildOpts struct {
 	Labels     map[string]string
 	Platform   string
 	Tags       []string
+	Target     string
 }
 
 func Build(ctx context.Context, path string, opts BuildOpts) error {
@@ -40,6 +41,9 @@ func Build(ctx context.Context, path string, opts BuildOpts) error {
 	if opts.Dockerfile != "" {
 		cmd.Args = append(cmd.Args, "-f", filepath.Join(path, opts.Dockerfile))
 	}
+	if opts.Target != "" {
+		cmd.Args = append(cmd.Args, "--target", opts.Target)
+	}
 	for k, v := range opts.BuildArgs {
 		cmd.Args = append(cmd.Args, "--build-arg", fmt.Sprintf("%s=%s", k, *v))
 	}
@@ -153,6 +153,35 @@ func IsValidNameMsgs(s string) []string {
 	return validation.IsDNS1035Label(s)
 }
 
+// Checks if the given key is a label or annotation key: a name of at most 63
+// characters with an optional DNS subdomain prefix, e.g. example.com/team.
+func IsValidLabelKey(s string) bool {
+	return len(validation.IsQualifiedName(s)) == 0
+}
+
+// Checks if the given value is a label value: at most 63 characters that
+// start and end with an alphanumeric character, or empty.
+func IsValidLabelValue(s string) bool {
+	return len(validation.IsValidLabelValue(s)) == 0
+}
+
+// annotationsMaxSize is the limit on the total size of the keys and values of
+// the annotations of a resource.
+const annotationsMaxSize = 256 * 1024
+
+// Checks if the given annotations have valid keys, and fit in a resource.
+// Annotation values can be any string.
+func IsValidAnnotations(annotations map[string]string) bool {
+	size := 0
+	for k, v := range annotations {
+		if !IsValidLabelKey(strings.ToLower(k)) {
+			return false
+		}
+		size += len(k) + len(v)
+	}
+	return size <= annotationsMaxSize
+}
+
 // ToValidName attempts to convert the provided string into an alternate version
 // that is a label name as defined in RFC 1123.
 // Basically the string must:
@@ -1,6 +1,7 @@
 package kubevalidate
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -189,3 +190,35 @@ func TestToValidCronjobName(t *testing.T) {
 		})
 	}
 }
+
+func TestLabelsAndAnnotations(t *testing.T) {
+	testCases := []struct {
+		key, value string
+		validLabel bool
+	}{
+		{"team", "payments", true},
+		{"example.com/cost-center", "cc-1234", true},
+		{"owner", "", true},
+		{"Team", "Payments_1.0", true},
+		{"example.com/", "payments", false},
+		{"team/owner/name", "payments", false},
+		{"-team", "payments", false},
+		{"team", "payments team", false},
+		{"team", strings.Repeat("a", 64), false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.key+"="+testCase.value, func(t *testing.T) {
+			valid := IsValidLabelKey(testCase.key) && IsValidLabelValue(testCase.value)
+			assert.Equal(t, testCase.validLabel, valid)
+		})
+	}
+
+	assert.True(t, IsValidAnnotations(map[string]string{
+		"example.com/description": "Handles payments, refunds & disputes",
+	}))
+	assert.False(t, IsValidAnnotations(map[string]string{"example.com/": "a"}))
+	assert.False(t, IsValidAnnotations(map[string]string{
+		"example.com/blob": strings.Repeat("a", 256*1024),
+	}))
+}
@@ -0,0 +1,47 @@
+package komponents
+
+import (
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Certificate is a cert-manager Certificate, which keeps a TLS certificate for
+// DNSNames in the secret SecretName.
+type Certificate struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	DNSNames    []string
+	SecretName  string
+	IssuerName  string
+	IssuerKind  string // Issuer or ClusterIssuer
+}
+
+// Certificate implements interface Resource (compile-time check)
+var _ reaktor.Resource = (*Certificate)(nil)
+
+func (c *Certificate) ToManifest() (any, error) {
+	dnsNames := make([]any, 0, len(c.DNSNames))
+	for _, name := range c.DNSNames {
+		dnsNames = append(dnsNames, name)
+	}
+	manifest := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   objectMetadata(c.Name, c.Namespace, c.Labels),
+			"spec": map[string]any{
+				"dnsNames":   dnsNames,
+				"secretName": c.SecretName,
+				"issuerRef": map[string]any{
+					"name":  c.IssuerName,
+					"kind":  c.IssuerKind,
+					"group": "cert-manager.io",
+				},
+			},
+		},
+	}
+	setAnnotations(manifest, c.Annotations)
+	return manifest, nil
+}
@@ -1,6 +1,11 @@
 package komponents
 
-import "fmt"
+import (
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
 
 const secretsMountPath = "/var/run/secrets/jetpack.io"
 const configMapMountPath = "/var/run/config/jetpack.io"
@@ -107,10 +112,33 @@ func (c *ConfigRef) ToVolumeMounts() []map[string]any {
 	return res
 }
 
+// ToFileVolumeMounts mounts keys of the ConfigMap as individual files, so that
+// they can be placed anywhere in the container without hiding the rest of the
+// directory. mountPaths maps each mount path to its key. Use it with the
+// volumes from ToVolumes.
+func (c *ConfigRef) ToFileVolumeMounts(mountPaths map[string]string) []map[string]any {
+	res := []map[string]any{}
+	if c == nil || c.ConfigMapRef == "" {
+		return res
+	}
+
+	paths := maps.Keys(mountPaths)
+	slices.Sort(paths)
+	for _, p := range paths {
+		res = append(res, map[string]any{
+			"name":      c.configMapMountName(),
+			"mountPath": p,
+			"subPath":   mountPaths[p],
+			"readOnly":  true,
+		})
+	}
+	return res
+}
+
 func (c *ConfigRef) secretMountName() string {
 	return fmt.Sprintf("secret-mount-%s", c.SecretsRef)
 }
 
 func (c *ConfigRef) configMapMountName() string {
-	return fmt.Sprintf("config-map-mount-%s", c.SecretsRef)
+	return fmt.Sprintf("config-map-mount-%s", c.ConfigMapRef)
 }
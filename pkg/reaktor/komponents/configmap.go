@@ -6,24 +6,56 @@ import (
 )
 
 type ConfigMap struct {
-	Name      string
-	Namespace string
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Data        map[string]string
 }
 
 // ConfigMap implements interface Resource (compile-time check)
 var _ reaktor.Resource = (*ConfigMap)(nil)
 
-// ToManifest is only structured for reading. For creating configMaps more work
-// needs to be done.
 func (ns *ConfigMap) ToManifest() (any, error) {
-	return &unstructured.Unstructured{
+	manifest := &unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": "v1",
 			"kind":       "ConfigMap",
-			"metadata": map[string]any{
-				"name":      ns.Name,
-				"namespace": ns.Namespace,
-			},
+			"metadata":   objectMetadata(ns.Name, ns.Namespace, ns.Labels),
 		},
-	}, nil
+	}
+	if len(ns.Data) > 0 {
+		manifest.Object["data"] = toAnyMap(ns.Data)
+	}
+	setAnnotations(manifest, ns.Annotations)
+	return manifest, nil
+}
+
+// objectMetadata returns the metadata of a namespaced object, with its labels
+// if there are any.
+func objectMetadata(name, namespace string, labels map[string]string) map[string]any {
+	metadata := map[string]any{
+		"name":      name,
+		"namespace": namespace,
+	}
+	if len(labels) > 0 {
+		metadata["labels"] = toAnyMap(labels)
+	}
+	return metadata
+}
+
+// setAnnotations sets the annotations of manifest, if there are any.
+func setAnnotations(manifest *unstructured.Unstructured, annotations map[string]string) {
+	if len(annotations) > 0 {
+		manifest.SetAnnotations(annotations)
+	}
+}
+
+// toAnyMap converts m so that unstructured objects can deep copy it.
+func toAnyMap(m map[string]string) map[string]any {
+	result := make(map[string]any, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
 }
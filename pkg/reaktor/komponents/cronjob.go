@@ -9,6 +9,7 @@ type CronJob struct {
 	Name           string
 	Namespace      string
 	Labels         map[string]string
+	Annotations    map[string]string
 	ContainerImage string
 	Schedule       string // the crontab string
 	Command        []string
@@ -22,7 +23,7 @@ var _ reaktor.Resource = (*CronJob)(nil)
 func (j *CronJob) ToManifest() (any, error) {
 	// TODO: should we cache the manifest like Job does?
 
-	return &unstructured.Unstructured{
+	manifest := &unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": "batch/v1",
 			"kind":       "CronJob",
@@ -54,7 +55,9 @@ func (j *CronJob) ToManifest() (any, error) {
 				},
 			},
 		},
-	}, nil
+	}
+	setAnnotations(manifest, j.Annotations)
+	return manifest, nil
 }
 
 func (j *CronJob) envConfig() EnvConfig {
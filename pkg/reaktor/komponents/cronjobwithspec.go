@@ -16,6 +16,7 @@ type CronJobWithSpec struct {
 	Name        string
 	Namespace   string
 	Labels      map[string]string
+	Annotations map[string]string
 	Schedule    string
 	Command     []string
 	PodMetadata map[string]any
@@ -32,6 +33,7 @@ func (j *CronJobWithSpec) ToManifest() (any, error) {
 		Name:        j.Name,
 		Namespace:   j.Namespace,
 		Labels:      j.Labels,
+		Annotations: j.Annotations,
 		Schedule:    j.Schedule,
 		PodMetadata: j.PodMetadata,
 	}
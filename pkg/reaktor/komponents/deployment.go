@@ -22,10 +22,11 @@ type DeploymentSpec struct {
 }
 
 type Deployment struct {
-	Name      string
-	Namespace string
-	Labels    map[string]string
-	Spec      DeploymentSpec
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Spec        DeploymentSpec
 }
 
 func (d *Deployment) ToManifest() (any, error) {
@@ -41,6 +42,7 @@ func (d *Deployment) ToManifest() (any, error) {
 			"spec": d.Spec,
 		},
 	}
+	setAnnotations(manifest, d.Annotations)
 
 	return manifest, nil
 }
@@ -9,43 +9,56 @@ import (
 
 const certmanagerNamespace = "cert-manager"
 
+// Host is an Ambassador Host, which terminates TLS for a hostname. Its
+// certificate is either in TLSSecretName, e.g. from a cert-manager
+// Certificate, or requested by Ambassador itself over ACME with ACMEEmail.
 type Host struct {
-	Hostname  string
-	Namespace string
+	Name          string // defaults to the hostname with dashes instead of dots
+	Hostname      string
+	Namespace     string
+	Labels        map[string]string
+	Annotations   map[string]string
+	ACMEEmail     string
+	TLSSecretName string
 }
 
-func HostInDefaultNamespace(h string) *Host {
+func HostInDefaultNamespace(h, acmeEmail string) *Host {
 	return &Host{
 		Hostname:  h,
 		Namespace: certmanagerNamespace,
+		ACMEEmail: acmeEmail,
 	}
 }
 
-// Secret implements interface Resource (compile-time check)
+// Host implements interface Resource (compile-time check)
 var _ reaktor.Resource = (*Host)(nil)
 
-// ToManifest is only structured for reading. For creating secrets more work
-// needs to be done.
-func (ns *Host) ToManifest() (any, error) {
-	name := strings.ReplaceAll(ns.Hostname, ".", "-")
-	return &unstructured.Unstructured{
+func (h *Host) ToManifest() (any, error) {
+	name := h.Name
+	if name == "" {
+		name = strings.ReplaceAll(h.Hostname, ".", "-")
+	}
+	spec := map[string]any{
+		"hostname": h.Hostname,
+		"tls": map[string]any{
+			"min_tls_version": "v1.2",
+			"alpn_protocols":  "h2,http/1.1",
+		},
+	}
+	if h.TLSSecretName != "" {
+		spec["tlsSecret"] = map[string]any{"name": h.TLSSecretName}
+		spec["acmeProvider"] = map[string]any{"authority": "none"}
+	} else {
+		spec["acmeProvider"] = map[string]any{"email": h.ACMEEmail}
+	}
+	manifest := &unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": "getambassador.io/v3alpha1",
 			"kind":       "Host",
-			"metadata": map[string]any{
-				"name":      name,
-				"namespace": ns.Namespace,
-			},
-			"spec": map[string]any{
-				"hostname": ns.Hostname,
-				"acmeProvider": map[string]any{
-					"email": "eng@jetpack.io",
-				},
-				"tls": map[string]any{
-					"min_tls_version": "v1.2",
-					"alpn_protocols":  "h2,http/1.1",
-				},
-			},
+			"metadata":   objectMetadata(name, h.Namespace, h.Labels),
+			"spec":       spec,
 		},
-	}, nil
+	}
+	setAnnotations(manifest, h.Annotations)
+	return manifest, nil
 }
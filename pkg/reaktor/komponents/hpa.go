@@ -24,10 +24,17 @@ type Metric struct {
 	Resource MetricResource
 }
 
+// DefaultHPAApiVersion is the HorizontalPodAutoscaler apiVersion used if
+// ApiVersion is not set. Clusters older than kubernetes 1.23 only serve
+// autoscaling/v2beta2, which has the same spec.
+const DefaultHPAApiVersion = "autoscaling/v2"
+
 type HorizontalPodAutoscaler struct {
+	ApiVersion     string
 	Name           string
 	Namespace      string
 	Labels         map[string]string
+	Annotations    map[string]string
 	ScaleTargetRef ScaleTargetRef
 	MinReplicas    int
 	MaxReplicas    int
@@ -57,11 +64,14 @@ func (hpa *HorizontalPodAutoscaler) ToManifest() (any, error) {
 		})
 	}
 
+	apiVersion := hpa.ApiVersion
+	if apiVersion == "" {
+		apiVersion = DefaultHPAApiVersion
+	}
+
 	manifest := &unstructured.Unstructured{
 		Object: map[string]any{
-			// This version is deprecated in 1.24 but we still need to support 1.23
-			// Maybe we can get cluster info and choose right version?
-			"apiVersion": "autoscaling/v2beta2",
+			"apiVersion": apiVersion,
 			"kind":       "HorizontalPodAutoscaler",
 			"metadata": map[string]any{
 				"name":      hpa.Name,
@@ -80,5 +90,6 @@ func (hpa *HorizontalPodAutoscaler) ToManifest() (any, error) {
 			},
 		},
 	}
+	setAnnotations(manifest, hpa.Annotations)
 	return manifest, nil
 }
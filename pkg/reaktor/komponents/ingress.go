@@ -0,0 +1,71 @@
+package komponents
+
+import (
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Ingress routes the requests to its hostnames to a service, and terminates
+// TLS with the certificate in TLSSecretName.
+type Ingress struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	// ClassName is the ingress class. Empty means the cluster's default class.
+	ClassName     string
+	Hostnames     []string
+	ServiceName   string
+	ServicePort   int
+	TLSSecretName string
+}
+
+// Ingress implements interface Resource (compile-time check)
+var _ reaktor.Resource = (*Ingress)(nil)
+
+func (ing *Ingress) ToManifest() (any, error) {
+	backend := map[string]any{
+		"service": map[string]any{
+			"name": ing.ServiceName,
+			"port": map[string]any{"number": int64(ing.ServicePort)},
+		},
+	}
+	var rules, hosts []any
+	for _, hostname := range ing.Hostnames {
+		rules = append(rules, map[string]any{
+			"host": hostname,
+			"http": map[string]any{
+				"paths": []any{
+					map[string]any{
+						"path":     "/",
+						"pathType": "Prefix",
+						"backend":  backend,
+					},
+				},
+			},
+		})
+		hosts = append(hosts, hostname)
+	}
+	spec := map[string]any{"rules": rules}
+	if ing.ClassName != "" {
+		spec["ingressClassName"] = ing.ClassName
+	}
+	if ing.TLSSecretName != "" {
+		spec["tls"] = []any{
+			map[string]any{
+				"hosts":      hosts,
+				"secretName": ing.TLSSecretName,
+			},
+		}
+	}
+	manifest := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata":   objectMetadata(ing.Name, ing.Namespace, ing.Labels),
+			"spec":       spec,
+		},
+	}
+	setAnnotations(manifest, ing.Annotations)
+	return manifest, nil
+}
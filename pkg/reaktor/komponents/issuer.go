@@ -0,0 +1,60 @@
+package komponents
+
+import (
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LetsEncryptServer is the ACME server of Let's Encrypt, which issues the
+// certificates of an Issuer if ACMEServer is not set.
+const LetsEncryptServer = "https://acme-v02.api.letsencrypt.org/directory"
+
+// Issuer is a cert-manager Issuer that gets certificates over ACME. It solves
+// HTTP-01 challenges with an Ingress of IngressClassName.
+type Issuer struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	ACMEEmail   string
+	ACMEServer  string
+	// IngressClassName is the class of the challenge Ingresses. Empty means the
+	// cluster's default class.
+	IngressClassName string
+}
+
+// Issuer implements interface Resource (compile-time check)
+var _ reaktor.Resource = (*Issuer)(nil)
+
+func (i *Issuer) ToManifest() (any, error) {
+	server := i.ACMEServer
+	if server == "" {
+		server = LetsEncryptServer
+	}
+	solver := map[string]any{}
+	if i.IngressClassName != "" {
+		solver["ingressClassName"] = i.IngressClassName
+	}
+	manifest := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Issuer",
+			"metadata":   objectMetadata(i.Name, i.Namespace, i.Labels),
+			"spec": map[string]any{
+				"acme": map[string]any{
+					"email":  i.ACMEEmail,
+					"server": server,
+					// The account key of the issuer
+					"privateKeySecretRef": map[string]any{"name": i.Name + "-account-key"},
+					"solvers": []any{
+						map[string]any{
+							"http01": map[string]any{"ingress": solver},
+						},
+					},
+				},
+			},
+		},
+	}
+	setAnnotations(manifest, i.Annotations)
+	return manifest, nil
+}
@@ -10,6 +10,7 @@ type Job struct {
 	Name                    string // TODO: ensure it's a valid k8s name
 	Namespace               string
 	Labels                  map[string]string
+	Annotations             map[string]string
 	ContainerImage          string
 	Command                 []string
 	EnvConfig               EnvConfig
@@ -85,6 +86,8 @@ func (j *Job) ToManifest() (any, error) {
 		},
 	}
 
+	setAnnotations(j.manifest, j.Annotations)
+
 	// merge the patch
 	j.manifest.Object = j.mergePatch(j.manifest.Object)
 
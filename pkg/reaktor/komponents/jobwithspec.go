@@ -15,6 +15,7 @@ type JobWithSpec struct {
 	Name                    string
 	Namespace               string
 	Labels                  map[string]string
+	Annotations             map[string]string
 	PodMetadata             map[string]any
 	PodSpec                 map[string]any
 	BackoffLimit            int
@@ -32,6 +33,7 @@ func (j *JobWithSpec) ToManifest() (any, error) {
 		Name:                    j.Name,
 		Namespace:               j.Namespace,
 		Labels:                  j.Labels,
+		Annotations:             j.Annotations,
 		PodMetadata:             j.PodMetadata,
 		BackoffLimit:            j.BackoffLimit,
 		TTLSecondsAfterFinished: j.TTLSecondsAfterFinished,
@@ -0,0 +1,42 @@
+package komponents
+
+import (
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Mapping is an Ambassador Mapping, which routes the requests to a hostname
+// to a service.
+type Mapping struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Hostname    string
+	Prefix      string // defaults to /
+	Service     string // e.g. my-svc.my-namespace:8080
+}
+
+// Mapping implements interface Resource (compile-time check)
+var _ reaktor.Resource = (*Mapping)(nil)
+
+func (m *Mapping) ToManifest() (any, error) {
+	prefix := m.Prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	manifest := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "getambassador.io/v3alpha1",
+			"kind":       "Mapping",
+			"metadata":   objectMetadata(m.Name, m.Namespace, m.Labels),
+			"spec": map[string]any{
+				"hostname": m.Hostname,
+				"prefix":   prefix,
+				"service":  m.Service,
+			},
+		},
+	}
+	setAnnotations(manifest, m.Annotations)
+	return manifest, nil
+}
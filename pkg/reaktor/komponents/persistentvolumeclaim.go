@@ -0,0 +1,52 @@
+package komponents
+
+import (
+	"go.jetpack.io/launchpad/pkg/reaktor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type PersistentVolumeClaim struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	// Size is a Kubernetes quantity, e.g. 10Gi
+	Size       string
+	AccessMode string
+	// StorageClass is optional. Empty means the cluster's default.
+	StorageClass string
+}
+
+// PersistentVolumeClaim implements interface Resource (compile-time check)
+var _ reaktor.Resource = (*PersistentVolumeClaim)(nil)
+
+func (pvc *PersistentVolumeClaim) ToManifest() (any, error) {
+	metadata := map[string]any{
+		"name":      pvc.Name,
+		"namespace": pvc.Namespace,
+	}
+	if len(pvc.Labels) > 0 {
+		metadata["labels"] = toAnyMap(pvc.Labels)
+	}
+	spec := map[string]any{
+		"accessModes": []any{pvc.AccessMode},
+		"resources": map[string]any{
+			"requests": map[string]any{
+				"storage": pvc.Size,
+			},
+		},
+	}
+	if pvc.StorageClass != "" {
+		spec["storageClassName"] = pvc.StorageClass
+	}
+	manifest := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   metadata,
+			"spec":       spec,
+		},
+	}
+	setAnnotations(manifest, pvc.Annotations)
+	return manifest, nil
+}
@@ -6,10 +6,12 @@ import (
 )
 
 type Secret struct {
-	Name      string
-	Namespace string
-	Type      string
-	Data      map[string]any
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Type        string
+	Data        map[string]any
 }
 
 // Secret implements interface Resource (compile-time check)
@@ -18,7 +20,7 @@ var _ reaktor.Resource = (*Secret)(nil)
 // ToManifest is only structured for reading. For creating secrets more work
 // needs to be done.
 func (ns *Secret) ToManifest() (any, error) {
-	return &unstructured.Unstructured{
+	manifest := &unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": "v1",
 			"kind":       "Secret",
@@ -29,14 +31,23 @@ func (ns *Secret) ToManifest() (any, error) {
 			"type": ns.Type,
 			"data": ns.Data,
 		},
-	}, nil
+	}
+	if len(ns.Labels) > 0 {
+		manifest.SetLabels(ns.Labels)
+	}
+	setAnnotations(manifest, ns.Annotations)
+	return manifest, nil
 }
 
 func SecretFromUnstructured(u *unstructured.Unstructured) *Secret {
 	return &Secret{
 		Name:      u.GetName(),
 		Namespace: u.GetNamespace(),
-		Type:      u.Object["type"].(string),
-		Data:      u.Object["data"].(map[string]any),
+		// Keep the labels and annotations, so that applying the secret again
+		// doesn't remove them.
+		Labels:      u.GetLabels(),
+		Annotations: u.GetAnnotations(),
+		Type:        u.Object["type"].(string),
+		Data:        u.Object["data"].(map[string]any),
 	}
 }
@@ -19,6 +19,7 @@ type Service struct {
 	Name           string
 	Namespace      string
 	Labels         map[string]string
+	Annotations    map[string]string
 	Ports          []Port
 	SelectorLabels map[string]string
 	Type           string // TODO make enum: ClusterIP, NodePort, LoadBalancer, ExternalName
@@ -51,5 +52,6 @@ func (svc *Service) ToManifest() (any, error) {
 			},
 		},
 	}
+	setAnnotations(manifest, svc.Annotations)
 	return manifest, nil
 }
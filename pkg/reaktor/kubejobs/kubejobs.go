@@ -51,6 +51,31 @@ func IsJobTerminated(e watch.Event, targetJob *unstructured.Unstructured) (bool,
 	return false, nil
 }
 
+// IsJobFinished returns true once the job has completed or failed. Unlike
+// IsJobTerminated, it also handles jobs that had already finished when the
+// watch started, which are only seen in an Added event.
+func IsJobFinished(e watch.Event) (bool, error) {
+	switch e.Type {
+	case watch.Added, watch.Modified:
+	case watch.Deleted:
+		return true, errors.New("job was deleted before it finished")
+	default:
+		return true, errors.Errorf("did not expect job watch.Event to be %s", e.Type)
+	}
+
+	job, err := jobFromEvent(&e)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status == "True" &&
+			(c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func IsJobCompleted(e *watch.Event) (bool, error) {
 	isFailedWithReason, err := IsJobFailedWithReason(e)
 	if err != nil {